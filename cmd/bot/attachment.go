@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"path"
+
+	"github.com/imdevinc/mylife/pkg/blob"
+	"github.com/imdevinc/mylife/pkg/bot"
+	"github.com/imdevinc/mylife/pkg/database"
+	"github.com/imdevinc/mylife/pkg/transcribe"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// saveAttachment downloads the file sent with the message
+// and saves it in the blob store
+func saveAttachment(ctx context.Context, telegram *bot.Telegram, store blob.Store, msg bot.MessageResponse, timestamp int64) (*database.Attachment, error) {
+	body, filePath, err := telegram.DownloadFile(msg.Attachment.FileID)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	name := fmt.Sprintf("%s/%d-%s%s", msg.QuestionKey, timestamp, msg.Attachment.UniqueID, path.Ext(filePath))
+	location, err := store.Put(ctx, name, body)
+	if err != nil {
+		return nil, err
+	}
+	return &database.Attachment{
+		Kind:     msg.Attachment.Kind,
+		Path:     location,
+		Caption:  msg.Text,
+		Width:    msg.Attachment.Width,
+		Height:   msg.Attachment.Height,
+		Size:     msg.Attachment.Size,
+		Duration: msg.Attachment.Duration,
+		MimeType: msg.Attachment.MimeType,
+	}, nil
+}
+
+// transcribeVoice fills in the transcript of a voice note. Failing to
+// transcribe isn't fatal since the audio itself is saved.
+func transcribeVoice(ctx context.Context, transcriber transcribe.Transcriber, attachment *database.Attachment) {
+	if transcriber == nil || attachment.Kind != "voice" {
+		return
+	}
+	transcript, err := transcriber.Transcribe(ctx, attachment.Path)
+	if err != nil {
+		log.WithError(err).Error("failed to transcribe voice note")
+		return
+	}
+	attachment.Transcript = transcript
+}
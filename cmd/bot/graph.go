@@ -0,0 +1,254 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/imdevinc/mylife/pkg/bot"
+	"github.com/imdevinc/mylife/pkg/chart"
+	"github.com/imdevinc/mylife/pkg/database"
+	"github.com/imdevinc/mylife/pkg/lifesheet"
+	"github.com/imdevinc/mylife/pkg/scheduler"
+	"github.com/imdevinc/mylife/pkg/stats"
+)
+
+// defaultAverageDays is the window of the "ma" smoothing option
+const defaultAverageDays = 7
+
+// defaultGraphRange is how far back to graph when no range is given
+const defaultGraphRange = 30 * 24 * time.Hour
+
+// smoothing is an overlay drawn over the raw values of a graph
+type smoothing struct {
+	name string
+	// days is the moving average window, 0 for other smoothings
+	days int
+}
+
+// graphRequest is a parsed /graph command
+type graphRequest struct {
+	keys []string
+	// since is how far back to graph
+	since      time.Duration
+	smoothings []smoothing
+}
+
+// parseGraph parses `/graph <key>[,<key>...] [90d] [options]`, the range
+// is anything scheduler.ParseDuration accepts
+func parseGraph(args []string) (graphRequest, error) {
+	if len(args) == 0 {
+		return graphRequest{}, fmt.Errorf("usage: /graph <key>[,<key>...] [90d] [ma7] [weekly] [trend]")
+	}
+	req := graphRequest{since: defaultGraphRange}
+	for _, k := range strings.Split(args[0], ",") {
+		if k != "" {
+			req.keys = append(req.keys, k)
+		}
+	}
+	if len(req.keys) == 0 {
+		return graphRequest{}, fmt.Errorf("no keys to graph")
+	}
+	options := []string{}
+	for _, a := range args[1:] {
+		a = strings.ToLower(a)
+		d, err := scheduler.ParseDuration(a)
+		if err != nil || d <= 0 {
+			options = append(options, a)
+			continue
+		}
+		req.since = d
+	}
+	smoothings, err := parseSmoothing(options)
+	if err != nil {
+		return graphRequest{}, err
+	}
+	req.smoothings = smoothings
+	return req, nil
+}
+
+// parseSmoothing parses the graph options: "ma" or "ma<days>" for a
+// moving average, "weekly" for weekly means and "trend" for a linear trend
+func parseSmoothing(options []string) ([]smoothing, error) {
+	out := []smoothing{}
+	for _, o := range options {
+		switch {
+		case o == "weekly" || o == "trend":
+			out = append(out, smoothing{name: o})
+		case strings.HasPrefix(o, "ma"):
+			days := defaultAverageDays
+			if raw := strings.TrimSuffix(strings.TrimPrefix(o, "ma"), "d"); raw != "" {
+				parsed, err := strconv.Atoi(raw)
+				if err != nil || parsed < 1 {
+					return nil, fmt.Errorf("invalid moving average %s, try ma7", o)
+				}
+				days = parsed
+			}
+			out = append(out, smoothing{name: "ma", days: days})
+		default:
+			return nil, fmt.Errorf("unknown graph option %s, try 90d, ma7, weekly or trend", o)
+		}
+	}
+	return out, nil
+}
+
+// overlays computes the smoothed series of the values, one per smoothing
+func overlays(values []float64, timestamps []int64, smoothings []smoothing) ([]chart.TimeSeries, error) {
+	times := make([]time.Time, len(timestamps))
+	for i, ts := range timestamps {
+		times[i] = time.Unix(ts, 0)
+	}
+	series := []chart.TimeSeries{}
+	for _, s := range smoothings {
+		switch s.name {
+		case "ma":
+			series = append(series, chart.TimeSeries{
+				Name:   fmt.Sprintf("%d day average", s.days),
+				Values: stats.MovingAverage(times, values, time.Duration(s.days)*24*time.Hour),
+				Width:  3,
+			})
+		case "weekly":
+			series = append(series, chart.TimeSeries{Name: "weekly mean", Values: stats.WeeklyMeans(times, values), Width: 3})
+		case "trend":
+			trend, err := stats.LinearTrend(times, values)
+			if err != nil {
+				return nil, fmt.Errorf("not enough values for a trend line. %v", err)
+			}
+			series = append(series, chart.TimeSeries{Name: "trend", Values: trend, Width: 2})
+		}
+	}
+	for i := range series {
+		series[i].Times = timestamps
+	}
+	return series, nil
+}
+
+// dualAxes decides if two series are too different to share a scale,
+// either because they don't overlap or one spans much more than the other
+func dualAxes(a []float64, b []float64) bool {
+	aMin, aMax := stats.Bounds(a)
+	bMin, bMax := stats.Bounds(b)
+	if aMax < bMin || bMax < aMin {
+		return true
+	}
+	aSpan, bSpan := aMax-aMin, bMax-bMin
+	return aSpan > 3*bSpan || bSpan > 3*aSpan
+}
+
+// sendGraph charts the past answers for the keys and sends the image.
+// A multiselect question is charted as how often each option was picked,
+// other questions are drawn as lines over time with optional smoothing
+// overlays. Two keys with very different values get their own axes.
+// Dates are shown in the location of now.
+func sendGraph(ctx context.Context, telegram *bot.Telegram, db database.Database, sheet *lifesheet.Lifesheet, now time.Time, args []string) error {
+	req, err := parseGraph(args)
+	if err != nil {
+		return err
+	}
+	since := now.Add(-req.since)
+	questions := []lifesheet.Question{}
+	for i, key := range req.keys {
+		// Keys are matched like /track, then queried as they are stored
+		q, ok := sheet.QuestionFold(key)
+		if !ok {
+			q = lifesheet.Question{Key: key}
+		}
+		req.keys[i] = q.Key
+		if q.Type == lifesheet.TypeMultiselect && len(req.keys) > 1 {
+			return fmt.Errorf("multiselect questions can't be graphed with other questions")
+		}
+		questions = append(questions, q)
+	}
+	answers, err := db.GetAnswersSince(ctx, req.keys, since)
+	if err != nil {
+		return fmt.Errorf("failed to get graph info from database. %s", err)
+	}
+	var url string
+	if questions[0].Type == lifesheet.TypeMultiselect {
+		if len(req.smoothings) > 0 {
+			return fmt.Errorf("multiselect questions can't be smoothed")
+		}
+		url = chart.BarURL(graphTitle(questions), questions[0].Buttons, database.OptionCounts(answers))
+	} else {
+		series, err := graphSeries(questions, answers, req.smoothings)
+		if err != nil {
+			return err
+		}
+		url = chart.TimeSeriesURL(graphTitle(questions), now.Location(), series...)
+	}
+	if err := telegram.SendImageURL(url); err != nil {
+		return fmt.Errorf("failed to send graph. %s", err)
+	}
+	return nil
+}
+
+// graphSeries builds a series of every question's values, followed by its
+// overlays. With two questions the second one may go on the right axis.
+func graphSeries(questions []lifesheet.Question, answers []database.AnswerResponse, smoothings []smoothing) ([]chart.TimeSeries, error) {
+	sort.SliceStable(answers, func(i, j int) bool {
+		return answers[i].Timestamp < answers[j].Timestamp
+	})
+	raw := []chart.TimeSeries{}
+	for _, q := range questions {
+		s := chart.TimeSeries{Name: questionLabel(q)}
+		for _, a := range answers {
+			if a.Key != q.Key {
+				continue
+			}
+			v, err := database.NumericValue(a)
+			if err != nil {
+				continue
+			}
+			s.Times = append(s.Times, a.Timestamp)
+			s.Values = append(s.Values, v)
+		}
+		if len(s.Values) == 0 {
+			return nil, fmt.Errorf("no answers to graph for %s", q.Key)
+		}
+		s.Values = chart.Normalize(q, s.Values)
+		raw = append(raw, s)
+	}
+	right := len(raw) == 2 && dualAxes(raw[0].Values, raw[1].Values)
+	series := []chart.TimeSeries{}
+	for i, s := range raw {
+		extra, err := overlays(s.Values, s.Times, smoothings)
+		if err != nil {
+			return nil, err
+		}
+		s.Right = right && i == 1
+		series = append(series, s)
+		for _, o := range extra {
+			if len(raw) > 1 {
+				o.Name = questions[i].Key + " " + o.Name
+			}
+			o.Right = s.Right
+			series = append(series, o)
+		}
+	}
+	return series, nil
+}
+
+// graphTitle uses the question text for a single question,
+// or compares the keys of several questions
+func graphTitle(questions []lifesheet.Question) string {
+	if len(questions) == 1 {
+		return questionLabel(questions[0])
+	}
+	keys := []string{}
+	for _, q := range questions {
+		keys = append(keys, q.Key)
+	}
+	return strings.Join(keys, " vs ")
+}
+
+// questionLabel is the question text, or the key for questions
+// that aren't in the lifesheet anymore
+func questionLabel(q lifesheet.Question) string {
+	if q.Text == "" {
+		return q.Key
+	}
+	return q.Text
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/imdevinc/mylife/pkg/lifesheet"
+)
+
+// lint validates the given lifesheet files or directories, printing
+// every problem found.
+// It returns the exit code, which is non-zero if any file is invalid.
+func lint(files []string) int {
+	if len(files) == 0 {
+		files = []string{"lifesheet.json"}
+	}
+	code := 0
+	for _, file := range files {
+		sheet, err := lifesheet.Load(file)
+		if err != nil {
+			fmt.Printf("%s: %s\n", file, err)
+			code = 1
+			continue
+		}
+		err = sheet.Validate()
+		var problems lifesheet.ValidationErrors
+		if errors.As(err, &problems) {
+			for _, p := range problems {
+				fmt.Printf("%s: %s\n", file, p)
+			}
+			code = 1
+			continue
+		}
+		fmt.Printf("%s: ok\n", file)
+	}
+	return code
+}
@@ -3,29 +3,40 @@ package main
 import (
 	"context"
 	"fmt"
+	"os"
 	"strings"
+	"time"
+	// Embed the timezone database, the container image doesn't ship one
+	_ "time/tzdata"
 
+	"github.com/imdevinc/mylife/pkg/blob"
 	"github.com/imdevinc/mylife/pkg/bot"
 	"github.com/imdevinc/mylife/pkg/config"
 	"github.com/imdevinc/mylife/pkg/database"
 	"github.com/imdevinc/mylife/pkg/lifesheet"
 	"github.com/imdevinc/mylife/pkg/scheduler"
+	"github.com/imdevinc/mylife/pkg/transcribe"
 
 	log "github.com/sirupsen/logrus"
 )
 
-const chartAPI string = "https://chart.googleapis.com/chart?cht=lc&chd=t:%s&chs=800x350&chl=%s&chtt=%s&chf=bg,s,e0e0e0&chco=000000,0000FF&chma=30,30,30,30&chds=%d,%d"
-
 func main() {
+	// `bot lint [files...]` only validates lifesheets, so it can run in CI
+	if len(os.Args) > 1 && os.Args[1] == "lint" {
+		os.Exit(lint(os.Args[2:]))
+	}
 	log.SetFormatter(&log.JSONFormatter{})
 	cfg, err := config.New()
 	if err != nil {
 		log.Fatal(err)
 	}
-	sheet, err := lifesheet.LoadFromFile(cfg.LifesheetFile)
+	sheet, err := lifesheet.Load(cfg.LifesheetFile)
 	if err != nil {
 		log.Fatal(err)
 	}
+	if err := sheet.Validate(); err != nil {
+		log.Fatal(err)
+	}
 	telegram, err := bot.New(&bot.BotConfig{Token: cfg.TelegramToken, ChatID: cfg.ChatID})
 	if err != nil {
 		log.Fatal(err)
@@ -40,52 +51,101 @@ func main() {
 	if err != nil {
 		log.Fatal(err)
 	}
-	scheduleCfg := scheduler.SchedulerConfig{
-		Bot:   telegram,
-		Sheet: sheet,
+	store, err := blob.NewLocalStore(cfg.BlobPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	var transcriber transcribe.Transcriber
+	if cfg.TranscribeCommand != "" {
+		transcriber, err = transcribe.NewCommand(cfg.TranscribeCommand)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+	location, err := time.LoadLocation(cfg.Timezone)
+	if err != nil {
+		log.Fatal(err)
+	}
+	sched := scheduler.New(&scheduler.SchedulerConfig{
+		Bot:        telegram,
+		Sheet:      sheet,
+		Database:   db,
+		Location:   location,
+		DigestTime: cfg.DigestTime,
+		AlertTime:  cfg.AlertTime,
+	})
+	if err := telegram.SetCommands(sched.Commands()); err != nil {
+		log.WithError(err).Error("failed to register bot commands")
 	}
 	go func() {
 		msgChan := telegram.Start()
 		for msg := range msgChan {
 			log.WithField("response", msg.Text).Debug("got response")
 			if msg.IsCommand && strings.HasPrefix(msg.Text, "graph ") {
-				key := strings.TrimPrefix(msg.Text, "graph ")
-				vals, err := db.GetValues(context.TODO(), key)
-				if err != nil {
-					log.WithError(err).Error("failed to save get values from database")
-					telegram.SendMessage(fmt.Sprintf("failed to get graph info from database. %s", err))
-					continue
-				}
-				url := fmt.Sprintf(chartAPI, strings.Join(vals.Values, ","), strings.Join(vals.Times, "%7C"), msg.QuestionKey, vals.Minimum, vals.Maximum)
-				if err := telegram.SendImageURL(url); err != nil {
+				args := strings.Fields(msg.Text)[1:]
+				if err := sendGraph(context.TODO(), telegram, db, sheet, sched.Now(), args); err != nil {
 					log.WithError(err).Error("failed to send graph")
-					telegram.SendMessage(fmt.Sprintf("failed to send graph. %s", err))
+					telegram.SendMessage(err.Error())
 				}
 				continue
 			}
 			if msg.IsCommand {
-				message := strings.ToLower(msg.Text)
-				go scheduler.ProcessCommand(&scheduleCfg, message)
+				go sched.ProcessCommand(msg.Text)
+				continue
+			}
+			if msg.Unprompted {
+				go sched.QuickLogShorthand(msg.Text)
 				continue
 			}
 
+			answer := lifesheet.ParsedAnswer{Text: msg.Text}
+			if q, ok := sheet.Question(msg.QuestionKey); ok {
+				answer, err = q.ParseAnswer(msg.Text)
+				if err != nil {
+					// Keep waiting for a valid answer to the same question
+					telegram.SendMessage(err.Error())
+					continue
+				}
+			}
+			var attachment *database.Attachment
+			if msg.Attachment != nil {
+				attachment, err = saveAttachment(context.TODO(), telegram, store, msg, sched.Now().Unix())
+				if err != nil {
+					log.WithError(err).Error("failed to save attachment")
+					telegram.SendMessage(fmt.Sprintf("failed to save attachment. %s", err))
+					continue
+				}
+				transcribeVoice(context.TODO(), transcriber, attachment)
+				if attachment.Transcript != "" {
+					answer.Text = attachment.Transcript
+				}
+			}
 			if err := db.SaveAnswer(context.TODO(), database.AnswerResponse{
-				Question: msg.Question,
-				Key:      msg.QuestionKey,
-				Answer:   msg.Text,
-				Source:   "telegram",
-				Type:     msg.Type,
+				Question:   msg.Question,
+				Key:        msg.QuestionKey,
+				Answer:     answer.Text,
+				Value:      answer.Value,
+				Values:     answer.Values,
+				Attachment: attachment,
+				Source:     "telegram",
+				Type:       msg.Type,
+				Timezone:   sched.Location().String(),
 			}); err != nil {
 				log.WithError(err).Error("failed to save results")
 				telegram.SendMessage(fmt.Sprintf("failed to save answer to database. %s", err))
+			} else {
+				go sched.CheckAlerts(msg.QuestionKey)
 			}
 			if msg.Acknowledge {
 				telegram.SendMessage("👍")
 			}
-			telegram.NextQuestion()
+			if msg.Type == lifesheet.TypeBoolean && answer.Text == "true" {
+				sched.ReplyStreak(msg.QuestionKey)
+			}
+			telegram.NextQuestion(answer.Text)
 		}
 	}()
-	if err := scheduler.Start(&scheduleCfg); err != nil {
+	if err := sched.Start(); err != nil {
 		log.Fatal(err)
 	}
 }
@@ -0,0 +1,50 @@
+package blob
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Store saves files attached to answers, such as photos
+type Store interface {
+	// Put saves the contents of r under name and returns
+	// the location the file can be found at
+	Put(ctx context.Context, name string, r io.Reader) (string, error)
+}
+
+// LocalStore is a Store that saves files on the local filesystem
+type LocalStore struct {
+	dir string
+}
+
+// NewLocalStore creates a LocalStore saving files under dir,
+// creating it if it doesn't exist
+func NewLocalStore(dir string) (*LocalStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create blob directory. %v", err)
+	}
+	return &LocalStore{dir: dir}, nil
+}
+
+func (s *LocalStore) Put(ctx context.Context, name string, r io.Reader) (string, error) {
+	path := filepath.Join(s.dir, filepath.Clean("/"+name))
+	if !strings.HasPrefix(path, filepath.Clean(s.dir)+string(filepath.Separator)) {
+		return "", fmt.Errorf("invalid blob name %s", name)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", fmt.Errorf("failed to create blob directory. %v", err)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to create blob. %v", err)
+	}
+	defer f.Close()
+	if _, err := io.Copy(f, r); err != nil {
+		return "", fmt.Errorf("failed to write blob. %v", err)
+	}
+	return path, nil
+}
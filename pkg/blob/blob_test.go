@@ -0,0 +1,41 @@
+package blob_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/imdevinc/mylife/pkg/blob"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocalStorePut(t *testing.T) {
+	dir := t.TempDir()
+	store, err := blob.NewLocalStore(dir)
+	if !assert.NoError(t, err, "expected no error") {
+		t.FailNow()
+	}
+	path, err := store.Put(context.TODO(), "meal/1-abc.jpg", strings.NewReader("photo"))
+	if !assert.NoError(t, err, "expected no error") {
+		t.FailNow()
+	}
+	assert.Equal(t, filepath.Join(dir, "meal", "1-abc.jpg"), path)
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "photo", string(data))
+}
+
+func TestLocalStoreStaysInDirectory(t *testing.T) {
+	dir := t.TempDir()
+	store, err := blob.NewLocalStore(filepath.Join(dir, "blobs"))
+	if !assert.NoError(t, err, "expected no error") {
+		t.FailNow()
+	}
+	path, err := store.Put(context.TODO(), "../../escape.jpg", strings.NewReader("photo"))
+	if !assert.NoError(t, err, "expected no error") {
+		t.FailNow()
+	}
+	assert.Equal(t, filepath.Join(dir, "blobs", "escape.jpg"), path)
+}
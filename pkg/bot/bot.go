@@ -2,6 +2,9 @@ package bot
 
 import (
 	"fmt"
+	"io"
+	"net/http"
+	"sort"
 	"strings"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
@@ -21,6 +24,9 @@ type Telegram struct {
 	WaitingForResponse bool
 	LastQuestion       AskedQuestion
 	skipRemaining      bool
+	questionMessageID  int
+	selected           map[string]bool
+	lastAnswer         string
 }
 
 type MessageResponse struct {
@@ -30,6 +36,22 @@ type MessageResponse struct {
 	Acknowledge bool
 	Question    string
 	Type        string
+	Attachment  *Attachment
+	// Unprompted is set for text sent while no question was asked
+	Unprompted bool
+}
+
+// Attachment is a file sent as an answer, such as a photo
+type Attachment struct {
+	// Kind is either "photo" or "voice"
+	Kind     string
+	FileID   string
+	UniqueID string
+	Width    int
+	Height   int
+	Size     int
+	Duration int
+	MimeType string
 }
 
 type AskedQuestion struct {
@@ -41,10 +63,20 @@ type AskedQuestion struct {
 	Buttons  map[string]string
 }
 
+// Command is a bot command shown in the Telegram command menu
+type Command struct {
+	Name        string
+	Description string
+}
+
 type MessageChannel chan MessageResponse
 
 const defaultTimeout int = 30
 
+// multiselectDone is the callback data of the button submitting
+// a multiselect question
+const multiselectDone string = "__done"
+
 func New(config *BotConfig) (*Telegram, error) {
 	bot, err := tgbotapi.NewBotAPI(config.Token)
 	if err != nil {
@@ -69,6 +101,7 @@ func (t *Telegram) Start() MessageChannel {
 			var messageID int
 			var text string
 			var location *tgbotapi.Location
+			var attachment *Attachment
 			if update.CallbackQuery != nil {
 				chatID = update.CallbackQuery.Message.Chat.ID
 				messageID = update.CallbackQuery.Message.MessageID
@@ -81,21 +114,75 @@ func (t *Telegram) Start() MessageChannel {
 				messageID = update.Message.MessageID
 				location = update.Message.Location
 				text = update.Message.Text
+				if photo := largestPhoto(update.Message.Photo); photo != nil {
+					text = update.Message.Caption
+					attachment = &Attachment{
+						Kind:     "photo",
+						FileID:   photo.FileID,
+						UniqueID: photo.FileUniqueID,
+						Width:    photo.Width,
+						Height:   photo.Height,
+						Size:     photo.FileSize,
+					}
+				} else if voice := update.Message.Voice; voice != nil {
+					text = update.Message.Caption
+					attachment = &Attachment{
+						Kind:     "voice",
+						FileID:   voice.FileID,
+						UniqueID: voice.FileUniqueID,
+						Size:     voice.FileSize,
+						Duration: voice.Duration,
+						MimeType: voice.MimeType,
+					}
+				}
 			} else {
 				return
 			}
-			t.ProcessMessage(chatID, messageID, text, location, ch)
+			t.ProcessMessage(chatID, messageID, text, location, attachment, ch)
 		}
 	}()
 	return ch
 }
 
+// largestPhoto picks the highest resolution version of a photo
+func largestPhoto(sizes []tgbotapi.PhotoSize) *tgbotapi.PhotoSize {
+	var largest *tgbotapi.PhotoSize
+	for i, p := range sizes {
+		if largest == nil || p.Width*p.Height > largest.Width*largest.Height {
+			largest = &sizes[i]
+		}
+	}
+	return largest
+}
+
+// DownloadFile fetches a file that was sent to the bot. It returns the
+// contents and the path Telegram stores the file under.
+func (t *Telegram) DownloadFile(fileID string) (io.ReadCloser, string, error) {
+	file, err := t.bot.GetFile(tgbotapi.FileConfig{FileID: fileID})
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get file. %v", err)
+	}
+	resp, err := http.Get(file.Link(t.bot.Token))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to download file. %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, "", fmt.Errorf("failed to download file. %s", resp.Status)
+	}
+	return resp.Body, file.FilePath, nil
+}
+
 func (t *Telegram) SendQuestion(message AskedQuestion) error {
 	log.Debug("sending message")
 	t.WaitingForResponse = true
 	t.LastQuestion = message
+	t.selected = map[string]bool{}
+	t.lastAnswer = ""
 	msg := tgbotapi.NewMessage(t.cfg.ChatID, message.Text)
-	if len(message.Buttons) > 0 {
+	if message.Type == "multiselect" {
+		msg.ReplyMarkup = t.multiselectKeyboard()
+	} else if len(message.Buttons) > 0 {
 		rows := [][]tgbotapi.InlineKeyboardButton{}
 		for k, v := range message.Buttons {
 			row := tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData(v, k))
@@ -115,10 +202,66 @@ func (t *Telegram) SendQuestion(message AskedQuestion) error {
 		keyb := tgbotapi.NewOneTimeReplyKeyboard([]tgbotapi.KeyboardButton{btn})
 		msg.ReplyMarkup = keyb
 	}
-	_, err := t.bot.Send(msg)
+	sent, err := t.bot.Send(msg)
 	if err != nil {
 		return err
 	}
+	t.questionMessageID = sent.MessageID
+	return nil
+}
+
+// multiselectKeyboard builds the keyboard for the current multiselect
+// question, marking the selected options and adding a button to submit
+func (t *Telegram) multiselectKeyboard() tgbotapi.InlineKeyboardMarkup {
+	options := []string{}
+	for k := range t.LastQuestion.Buttons {
+		options = append(options, k)
+	}
+	sort.Strings(options)
+	rows := [][]tgbotapi.InlineKeyboardButton{}
+	for _, k := range options {
+		label := t.LastQuestion.Buttons[k]
+		if t.selected[k] {
+			label = "✅ " + label
+		}
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData(label, k)))
+	}
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData("Done", multiselectDone)))
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// toggleOption selects or deselects a multiselect option and
+// updates the keyboard in place
+func (t *Telegram) toggleOption(option string) {
+	t.selected[option] = !t.selected[option]
+	edit := tgbotapi.NewEditMessageReplyMarkup(t.cfg.ChatID, t.questionMessageID, t.multiselectKeyboard())
+	if _, err := t.bot.Send(edit); err != nil {
+		log.WithError(err).Error("failed to update multiselect keyboard")
+	}
+}
+
+// selectedOptions returns the selected multiselect options
+// as a comma separated list
+func (t *Telegram) selectedOptions() string {
+	options := []string{}
+	for k, ok := range t.selected {
+		if ok {
+			options = append(options, k)
+		}
+	}
+	sort.Strings(options)
+	return strings.Join(options, ",")
+}
+
+// SetCommands registers the commands shown in the Telegram command menu
+func (t *Telegram) SetCommands(commands []Command) error {
+	botCommands := []tgbotapi.BotCommand{}
+	for _, c := range commands {
+		botCommands = append(botCommands, tgbotapi.BotCommand{Command: c.Name, Description: c.Description})
+	}
+	if _, err := t.bot.Request(tgbotapi.NewSetMyCommands(botCommands...)); err != nil {
+		return fmt.Errorf("failed to set commands. %v", err)
+	}
 	return nil
 }
 
@@ -133,7 +276,28 @@ func (t *Telegram) SendMessage(message string) error {
 	return nil
 }
 
-func (t *Telegram) ProcessMessage(chatID int64, messageID int, text string, location *tgbotapi.Location, ch chan MessageResponse) {
+// SendMessageWithButtons sends a message with inline buttons, keyed by
+// their callback data like AskedQuestion.Buttons. Callback data starting
+// with a slash is handled as a command.
+func (t *Telegram) SendMessageWithButtons(message string, buttons map[string]string) error {
+	msg := tgbotapi.NewMessage(t.cfg.ChatID, message)
+	data := []string{}
+	for k := range buttons {
+		data = append(data, k)
+	}
+	sort.Strings(data)
+	row := []tgbotapi.InlineKeyboardButton{}
+	for _, k := range data {
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(buttons[k], k))
+	}
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(row)
+	if _, err := t.bot.Send(msg); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *Telegram) ProcessMessage(chatID int64, messageID int, text string, location *tgbotapi.Location, attachment *Attachment, ch chan MessageResponse) {
 	if chatID != t.cfg.ChatID {
 		msg := tgbotapi.NewMessage(chatID, "This is not the bot you're looking for")
 		t.bot.Send(msg)
@@ -149,6 +313,12 @@ func (t *Telegram) ProcessMessage(chatID int64, messageID int, text string, loca
 		return
 	}
 
+	if t.LastQuestion.Key == "" && text != "" && attachment == nil && !strings.HasPrefix(text, "/") {
+		// This might be a `key value` quick log
+		ch <- MessageResponse{Text: text, Unprompted: true}
+		return
+	}
+
 	if t.LastQuestion.Key == "" {
 		msg := tgbotapi.NewMessage(t.cfg.ChatID, "I didn't ask a question")
 		if _, err := t.bot.Send(msg); err != nil {
@@ -157,6 +327,33 @@ func (t *Telegram) ProcessMessage(chatID int64, messageID int, text string, loca
 		return
 	}
 
+	isSkip := strings.ToLower(text) == "/skip" || strings.ToLower(text) == "/skip_all"
+	if t.LastQuestion.Type == "photo" && (attachment == nil || attachment.Kind != "photo") && !isSkip {
+		msg := tgbotapi.NewMessage(t.cfg.ChatID, "Please send a photo, or /skip")
+		if _, err := t.bot.Send(msg); err != nil {
+			log.WithError(err).Error("failed to send message")
+		}
+		return
+	}
+
+	if attachment != nil && attachment.Kind == "voice" && t.LastQuestion.Type != "text" {
+		msg := tgbotapi.NewMessage(t.cfg.ChatID, "Voice notes can only answer text questions")
+		if _, err := t.bot.Send(msg); err != nil {
+			log.WithError(err).Error("failed to send message")
+		}
+		return
+	}
+
+	if t.LastQuestion.Type == "multiselect" {
+		if _, ok := t.LastQuestion.Buttons[text]; ok {
+			t.toggleOption(text)
+			return
+		}
+		if text == multiselectDone {
+			text = t.selectedOptions()
+		}
+	}
+
 	if val, ok := t.LastQuestion.Replies[text]; ok {
 		msg := tgbotapi.NewMessage(t.cfg.ChatID, val)
 		msg.ReplyToMessageID = messageID
@@ -175,6 +372,7 @@ func (t *Telegram) ProcessMessage(chatID int64, messageID int, text string, loca
 		Question:    t.LastQuestion.Question,
 		Type:        t.LastQuestion.Type,
 		Acknowledge: true,
+		Attachment:  attachment,
 	}
 
 	if strings.ToLower(text) == "/skip_all" {
@@ -189,12 +387,22 @@ func (t *Telegram) ProcessMessage(chatID int64, messageID int, text string, loca
 	}
 }
 
-func (t *Telegram) NextQuestion() {
+// NextQuestion records the accepted answer to the current
+// question and allows the next one to be sent
+func (t *Telegram) NextQuestion(answer string) {
+	t.lastAnswer = answer
 	t.WaitingForResponse = false
 }
 
+// LastAnswer returns the accepted answer to the last question,
+// which is empty if it was skipped
+func (t *Telegram) LastAnswer() string {
+	return t.lastAnswer
+}
+
 func (t *Telegram) ResetQuestions() {
 	t.LastQuestion = AskedQuestion{}
+	t.selected = map[string]bool{}
 	t.WaitingForResponse = false
 	t.skipRemaining = false
 }
@@ -211,3 +419,13 @@ func (t *Telegram) SendImageURL(u string) error {
 	}
 	return nil
 }
+
+// SendImage uploads an image rendered by the bot, with an optional caption
+func (t *Telegram) SendImage(name string, data []byte, caption string) error {
+	photo := tgbotapi.NewPhoto(t.cfg.ChatID, tgbotapi.FileBytes{Name: name, Bytes: data})
+	photo.Caption = caption
+	if _, err := t.bot.Send(photo); err != nil {
+		return fmt.Errorf("failed to send image. %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,23 @@
+package bot
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProcessMessageAfterCheckIn(t *testing.T) {
+	telegram := &Telegram{cfg: &BotConfig{ChatID: 1}}
+	// A check-in asked and answered a question, then ended
+	telegram.LastQuestion = AskedQuestion{Key: "mood", Type: "range"}
+	telegram.WaitingForResponse = true
+	telegram.NextQuestion("5")
+	telegram.ResetQuestions()
+
+	ch := make(chan MessageResponse, 1)
+	telegram.ProcessMessage(1, 1, "weight 72.5", nil, nil, ch)
+	resp := <-ch
+	assert.True(t, resp.Unprompted)
+	assert.Equal(t, "", resp.QuestionKey)
+	assert.Equal(t, "weight 72.5", resp.Text)
+}
@@ -0,0 +1,238 @@
+package chart
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/imdevinc/mylife/pkg/lifesheet"
+	"github.com/imdevinc/mylife/pkg/stats"
+)
+
+const chartAPI string = "https://chart.googleapis.com/chart?cht=lc&chd=t:%s&chs=800x350&chl=%s&chtt=%s&chf=bg,s,e0e0e0&chco=000000,0000FF&chma=30,30,30,30&chds=%g,%g"
+
+const barAPI string = "https://chart.googleapis.com/chart?cht=bhs&chd=t:%s&chs=800x350&chxt=x,y&chxr=0,0,%d&chds=0,%d&chxl=1:%s&chtt=%s&chf=bg,s,e0e0e0&chco=0000FF&chma=30,30,30,30"
+
+const timeSeriesAPI string = "https://chart.googleapis.com/chart?cht=lxy&chs=800x350&chd=t:%s&chds=%s&chxt=%s&chxr=%s&chxl=0:%%7C%s&chco=%s&chls=%s&chdl=%s&chdlp=b&chtt=%s&chf=bg,s,e0e0e0&chma=30,30,30,30"
+
+// timeLabels is the number of dates shown on the x axis of time series
+const timeLabels = 5
+
+// maxTimeSeriesPoints keeps time series URLs short enough for the chart API
+const maxTimeSeriesPoints = 150
+
+// colors are used for the series of a chart, in order
+var colors = []string{"000000", "0000FF", "FF0000", "00AA00", "FF8800", "AA00AA"}
+
+const scatterAPI string = "https://chart.googleapis.com/chart?cht=s&chd=t:%s%%7C%s&chs=600x600&chxt=x,y,x,y&chxr=0,%g,%g%%7C1,%g,%g&chds=%g,%g,%g,%g&chxl=2:%%7C%s%%7C3:%%7C%s&chxp=2,50%%7C3,50&chtt=%s&chf=bg,s,e0e0e0&chco=0000FF&chma=30,30,30,30"
+
+const minutesPerDay = 24 * 60
+
+const secondsPerDay = minutesPerDay * 60
+
+// LineURL builds a Google Chart URL for a line chart of the values,
+// labelled with the given times
+func LineURL(title string, values []float64, times []string) string {
+	min, max := stats.Bounds(values)
+	return fmt.Sprintf(chartAPI, joinValues(values), strings.Join(times, "%7C"), url.QueryEscape(title), min, max)
+}
+
+// TimeSeries is a named line of values at the given unix times
+type TimeSeries struct {
+	Name   string
+	Times  []int64
+	Values []float64
+	// Width of the line in pixels, defaults to 1
+	Width int
+	// Right scales the series on the right axis instead of the left one
+	Right bool
+}
+
+// TimeSeriesURL builds a Google Chart URL for a line chart of series that
+// were recorded at different times. Series on the left and right axes each
+// share a scale, the x axis is labelled with dates in the location.
+func TimeSeriesURL(title string, loc *time.Location, series ...TimeSeries) string {
+	times := []float64{}
+	left := []float64{}
+	right := []float64{}
+	for _, s := range series {
+		for _, t := range s.Times {
+			times = append(times, float64(t))
+		}
+		if s.Right {
+			right = append(right, s.Values...)
+		} else {
+			left = append(left, s.Values...)
+		}
+	}
+	// A single day or value is drawn in the middle of the chart
+	xMin, xMax := widened(times, secondsPerDay)
+	leftMin, leftMax := widened(left, 1)
+	rightMin, rightMax := widened(right, 1)
+
+	data := []string{}
+	scales := []string{}
+	names := []string{}
+	styles := []string{}
+	lineColors := []string{}
+	for i, s := range series {
+		s = thin(s, maxTimeSeriesPoints)
+		// Days since the first value keep the numbers short
+		xs := make([]float64, len(s.Times))
+		for j, t := range s.Times {
+			xs[j] = (float64(t) - xMin) / secondsPerDay
+		}
+		data = append(data, joinValues(xs), joinValues(s.Values))
+		yMin, yMax := leftMin, leftMax
+		if s.Right {
+			yMin, yMax = rightMin, rightMax
+		}
+		scales = append(scales, fmt.Sprintf("0,%g,%g,%g", (xMax-xMin)/secondsPerDay, yMin, yMax))
+		names = append(names, url.QueryEscape(s.Name))
+		width := s.Width
+		if width == 0 {
+			width = 1
+		}
+		styles = append(styles, strconv.Itoa(width))
+		lineColors = append(lineColors, colors[i%len(colors)])
+	}
+	axes := "x,y"
+	ranges := fmt.Sprintf("1,%g,%g", leftMin, leftMax)
+	if len(right) > 0 {
+		axes = "x,y,r"
+		ranges += fmt.Sprintf("%%7C2,%g,%g", rightMin, rightMax)
+	}
+	labels := []string{}
+	for i := 0; i < timeLabels; i++ {
+		t := xMin + (xMax-xMin)*float64(i)/float64(timeLabels-1)
+		labels = append(labels, time.Unix(int64(t), 0).In(loc).Format("02-01"))
+	}
+	return fmt.Sprintf(timeSeriesAPI, strings.Join(data, "%7C"), strings.Join(scales, ","), axes, ranges,
+		strings.Join(labels, "%7C"), strings.Join(lineColors, ","), strings.Join(styles, "%7C"), strings.Join(names, "%7C"), url.QueryEscape(title))
+}
+
+// BarURL builds a Google Chart URL for a horizontal bar chart of how
+// many times each option was counted. Options are the keys of labels.
+func BarURL(title string, labels map[string]string, counts map[string]int) string {
+	options := []string{}
+	for k := range labels {
+		options = append(options, k)
+	}
+	sort.Strings(options)
+	values := []string{}
+	names := []string{}
+	max := 0
+	for _, k := range options {
+		values = append(values, strconv.Itoa(counts[k]))
+		if counts[k] > max {
+			max = counts[k]
+		}
+		// Axis labels go from the bottom up, bars from the top down
+		names = append([]string{url.QueryEscape(labels[k])}, names...)
+	}
+	if max == 0 {
+		max = 1
+	}
+	return fmt.Sprintf(barAPI, strings.Join(values, ","), max, max, "%7C"+strings.Join(names, "%7C"), url.QueryEscape(title))
+}
+
+// ScatterURL builds a Google Chart URL for a scatter plot of ys against xs,
+// with the axes labelled by their keys
+func ScatterURL(title string, xLabel string, yLabel string, xs []float64, ys []float64) string {
+	xMin, xMax := padded(stats.Bounds(xs))
+	yMin, yMax := padded(stats.Bounds(ys))
+	return fmt.Sprintf(scatterAPI, joinValues(xs), joinValues(ys), xMin, xMax, yMin, yMax, xMin, xMax, yMin, yMax,
+		url.QueryEscape(xLabel), url.QueryEscape(yLabel), url.QueryEscape(title))
+}
+
+// Normalize converts stored values into something readable on a chart.
+// Times of day become hours, durations become minutes.
+func Normalize(q lifesheet.Question, values []float64) []float64 {
+	switch q.Type {
+	case lifesheet.TypeTime:
+		return TimeOfDay(values)
+	case lifesheet.TypeDuration:
+		out := make([]float64, len(values))
+		for i, v := range values {
+			out[i] = v / 60
+		}
+		return out
+	}
+	return values
+}
+
+// TimeOfDay converts minutes since midnight into hours, keeping values
+// that cross midnight continuous. The day is cut at the largest gap between
+// values, so bedtimes between 22:00 and 01:00 become 22 to 25 instead of
+// jumping between 0 and 24.
+func TimeOfDay(minutes []float64) []float64 {
+	out := make([]float64, len(minutes))
+	if len(minutes) == 0 {
+		return out
+	}
+	sorted := append([]float64{}, minutes...)
+	sort.Float64s(sorted)
+	// The gap wrapping around midnight needs no shifting
+	cut := 0.0
+	largest := sorted[0] + minutesPerDay - sorted[len(sorted)-1]
+	for i := 1; i < len(sorted); i++ {
+		if gap := sorted[i] - sorted[i-1]; gap > largest {
+			largest = gap
+			cut = sorted[i]
+		}
+	}
+	for i, m := range minutes {
+		if m < cut {
+			m += minutesPerDay
+		}
+		out[i] = m / 60
+	}
+	return out
+}
+
+// padded widens the bounds so points aren't drawn on the edge of the chart
+func padded(min, max float64) (float64, float64) {
+	pad := (max - min) / 10
+	if pad == 0 {
+		pad = 1
+	}
+	return min - pad, max + pad
+}
+
+// thin keeps up to max evenly spaced points of the series,
+// always including the first and last ones
+func thin(s TimeSeries, max int) TimeSeries {
+	if len(s.Values) <= max {
+		return s
+	}
+	times := make([]int64, max)
+	values := make([]float64, max)
+	for i := range values {
+		j := i * (len(s.Values) - 1) / (max - 1)
+		times[i] = s.Times[j]
+		values[i] = s.Values[j]
+	}
+	s.Times, s.Values = times, values
+	return s
+}
+
+// widened returns the bounds of the values, padded if they have
+// no span as those can't be scaled
+func widened(values []float64, pad float64) (float64, float64) {
+	min, max := stats.Bounds(values)
+	if min == max {
+		return min - pad, max + pad
+	}
+	return min, max
+}
+
+func joinValues(values []float64) string {
+	out := make([]string, len(values))
+	for i, v := range values {
+		out[i] = strconv.FormatFloat(v, 'f', 2, 64)
+	}
+	return strings.Join(out, ",")
+}
@@ -0,0 +1,96 @@
+package chart_test
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/imdevinc/mylife/pkg/chart"
+	"github.com/imdevinc/mylife/pkg/stats"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimeOfDay(t *testing.T) {
+	tests := []struct {
+		name    string
+		minutes []float64
+		want    []float64
+	}{
+		{name: "empty", minutes: []float64{}, want: []float64{}},
+		{name: "mornings", minutes: []float64{420, 450, 390}, want: []float64{7, 7.5, 6.5}},
+		{name: "bedtimes crossing midnight", minutes: []float64{1380, 30, 1320, 60}, want: []float64{23, 24.5, 22, 25}},
+		{name: "bedtimes before midnight", minutes: []float64{1380, 1410}, want: []float64{23, 23.5}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, chart.TimeOfDay(tt.minutes))
+		})
+	}
+}
+
+func TestScatterURL(t *testing.T) {
+	u := chart.ScatterURL("sleep vs mood", "sleep_hours", "mood", []float64{6, 8}, []float64{2, 4})
+	assert.Contains(t, u, "cht=s&chd=t:6.00,8.00%7C2.00,4.00")
+	assert.Contains(t, u, "chds=5.8,8.2,1.8,4.2")
+	assert.Contains(t, u, "chxl=2:%7Csleep_hours%7C3:%7Cmood")
+}
+
+func TestTimeSeriesURL(t *testing.T) {
+	day := int64(24 * 60 * 60)
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC).Unix()
+	u := chart.TimeSeriesURL("mood vs sleep_hours", time.UTC,
+		chart.TimeSeries{Name: "How are you?", Times: []int64{start, start + 4*day}, Values: []float64{1, 5}},
+		chart.TimeSeries{Name: "How long did you sleep?", Times: []int64{start + day}, Values: []float64{7.5}, Right: true},
+	)
+	assert.Contains(t, u, "cht=lxy")
+	assert.Contains(t, u, "chd=t:0.00,4.00%7C1.00,5.00%7C1.00%7C7.50&chds=0,4,1,5,0,4,6.5,8.5")
+	assert.Contains(t, u, "chxt=x,y,r&chxr=1,1,5%7C2,6.5,8.5")
+	assert.Contains(t, u, "chxl=0:%7C01-01%7C02-01%7C03-01%7C04-01%7C05-01")
+	assert.Contains(t, u, "chdl=How+are+you%3F%7CHow+long+did+you+sleep%3F")
+
+	// A single answer still gets a range to be drawn in
+	u = chart.TimeSeriesURL("mood", time.UTC, chart.TimeSeries{Name: "mood", Times: []int64{start}, Values: []float64{3}})
+	assert.Contains(t, u, "chd=t:1.00%7C3.00&chds=0,2,2,4")
+	assert.Contains(t, u, "chxl=0:%7C31-12%7C31-12%7C01-01%7C01-01%7C02-01")
+
+	// Long histories are thinned out to keep the URL short
+	long := chart.TimeSeries{Name: "mood"}
+	for i := 0; i < 1000; i++ {
+		long.Times = append(long.Times, start+int64(i)*day)
+		long.Values = append(long.Values, float64(i%5))
+	}
+	u = chart.TimeSeriesURL("mood", time.UTC, long)
+	data := strings.Split(strings.Split(strings.Split(u, "chd=t:")[1], "&")[0], "%7C")
+	assert.Len(t, strings.Split(data[0], ","), 150)
+	assert.True(t, strings.HasSuffix(data[0], ",999.00"))
+	assert.Less(t, len(u), 4000)
+}
+
+func TestHeatmapPNG(t *testing.T) {
+	// Jan 1 2023 is a Sunday, so it's in the last row of the first column
+	days := map[stats.Day]float64{
+		{Year: 2023, Month: time.January, Day: 1}: 5,
+		{Year: 2023, Month: time.January, Day: 2}: 1,
+	}
+	data, err := chart.HeatmapPNG(2023, days, 1, 5)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, image.Rect(0, 0, 774, 116), img.Bounds())
+	cell := func(col, row int) color.Color {
+		return img.At(10+col*14+6, 10+row*14+6)
+	}
+	assert.Equal(t, color.RGBA{R: 0x21, G: 0x6e, B: 0x39, A: 0xff}, cell(0, 6))
+	assert.Equal(t, color.RGBA{R: 0x9b, G: 0xe9, B: 0xa8, A: 0xff}, cell(1, 0))
+	assert.Equal(t, color.RGBA{R: 0xeb, G: 0xed, B: 0xf0, A: 0xff}, cell(1, 1))
+	// Before the first day of the year
+	assert.Equal(t, color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}, cell(0, 0))
+}
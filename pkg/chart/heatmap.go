@@ -0,0 +1,81 @@
+package chart
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"time"
+
+	"github.com/imdevinc/mylife/pkg/stats"
+)
+
+const (
+	heatmapCell   = 12
+	heatmapGap    = 2
+	heatmapMargin = 10
+	// A year touches at most 54 weeks
+	heatmapWeeks = 54
+)
+
+var (
+	heatmapBackground = color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+	heatmapEmpty      = color.RGBA{R: 0xeb, G: 0xed, B: 0xf0, A: 0xff}
+	heatmapLow        = color.RGBA{R: 0x9b, G: 0xe9, B: 0xa8, A: 0xff}
+	heatmapHigh       = color.RGBA{R: 0x21, G: 0x6e, B: 0x39, A: 0xff}
+)
+
+// HeatmapPNG draws a calendar of the year with a column per week and a
+// row per weekday, starting on Monday. Days are colored from light to
+// dark green between min and max, days without a value are gray.
+func HeatmapPNG(year int, days map[stats.Day]float64, min float64, max float64) ([]byte, error) {
+	size := func(cells int) int {
+		return 2*heatmapMargin + cells*heatmapCell + (cells-1)*heatmapGap
+	}
+	img := image.NewRGBA(image.Rect(0, 0, size(heatmapWeeks), size(7)))
+	draw.Draw(img, img.Bounds(), &image.Uniform{C: heatmapBackground}, image.Point{}, draw.Src)
+
+	first := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+	offset := weekdayRow(first)
+	for t := first; t.Year() == year; t = t.AddDate(0, 0, 1) {
+		col := (t.YearDay() - 1 + offset) / 7
+		row := weekdayRow(t)
+		c := heatmapEmpty
+		if v, ok := days[stats.Day{Year: year, Month: t.Month(), Day: t.Day()}]; ok {
+			c = heatmapColor(v, min, max)
+		}
+		x := heatmapMargin + col*(heatmapCell+heatmapGap)
+		y := heatmapMargin + row*(heatmapCell+heatmapGap)
+		draw.Draw(img, image.Rect(x, y, x+heatmapCell, y+heatmapCell), &image.Uniform{C: c}, image.Point{}, draw.Src)
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("failed to encode heatmap. %v", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// weekdayRow returns the row of the day, Monday being the first
+func weekdayRow(t time.Time) int {
+	return (int(t.Weekday()) + 6) % 7
+}
+
+// heatmapColor interpolates between the low and high colors
+func heatmapColor(v float64, min float64, max float64) color.RGBA {
+	f := 1.0
+	if max > min {
+		f = (v - min) / (max - min)
+	}
+	if f < 0 {
+		f = 0
+	} else if f > 1 {
+		f = 1
+	}
+	mix := func(a, b uint8) uint8 {
+		return uint8(float64(a) + (float64(b)-float64(a))*f + 0.5)
+	}
+	return color.RGBA{R: mix(heatmapLow.R, heatmapHigh.R), G: mix(heatmapLow.G, heatmapHigh.G), B: mix(heatmapLow.B, heatmapHigh.B), A: 0xff}
+}
@@ -2,7 +2,9 @@ package config
 
 import (
 	"os"
+	"path/filepath"
 	"strconv"
+	"strings"
 
 	_ "github.com/joho/godotenv/autoload"
 )
@@ -12,7 +14,18 @@ type AppConfig struct {
 	TelegramToken string
 	ChatID        int64
 	CSVPath       string
-	Mongo         MongoConfig
+	BlobPath      string
+	// TranscribeCommand is run with the path of a voice note
+	// and prints its transcript, voice notes aren't transcribed if empty
+	TranscribeCommand string
+	Timezone          string
+	// DigestTime is when the daily and weekly digests are sent,
+	// "off" disables them
+	DigestTime string
+	// AlertTime is when every alert is checked each day,
+	// "off" disables the daily check
+	AlertTime string
+	Mongo     MongoConfig
 }
 
 type MongoConfig struct {
@@ -36,13 +49,72 @@ func New() (*AppConfig, error) {
 		Port:     os.Getenv("MONGO_PORT"),
 		Database: os.Getenv("MONGO_DB"),
 	}
+	timezone := os.Getenv("TIMEZONE")
+	if timezone == "" || timezone == "Local" {
+		timezone = hostTimezone()
+	}
+	lifesheetFile := os.Getenv("LIFESHEET_PATH")
+	if lifesheetFile == "" {
+		lifesheetFile = "lifesheet.json"
+	}
+	blobPath := os.Getenv("BLOB_PATH")
+	if blobPath == "" {
+		blobPath = "blobs"
+	}
+	digestTime := os.Getenv("DIGEST_TIME")
+	if digestTime == "" {
+		digestTime = "22:30"
+	} else if digestTime == "off" {
+		digestTime = ""
+	}
+	alertTime := os.Getenv("ALERT_TIME")
+	if alertTime == "" {
+		alertTime = "12:00"
+	} else if alertTime == "off" {
+		alertTime = ""
+	}
 	appConfig := AppConfig{
-		LifesheetFile: "lifesheet.json",
-		TelegramToken: os.Getenv("TELEGRAM_TOKEN"),
-		ChatID:        chatID,
-		CSVPath:       "database.csv",
-		Mongo:         mongoCfg,
+		LifesheetFile:     lifesheetFile,
+		TelegramToken:     os.Getenv("TELEGRAM_TOKEN"),
+		ChatID:            chatID,
+		CSVPath:           "database.csv",
+		BlobPath:          blobPath,
+		TranscribeCommand: os.Getenv("TRANSCRIBE_COMMAND"),
+		Timezone:          timezone,
+		DigestTime:        digestTime,
+		AlertTime:         alertTime,
+		Mongo:             mongoCfg,
 	}
 
 	return &appConfig, nil
 }
+
+// hostTimezone resolves the host's timezone to an IANA name. Answers store
+// their timezone, and "Local" would mean something else on every host.
+// It falls back to UTC if the zone can't be found.
+func hostTimezone() string {
+	if tz := strings.TrimPrefix(os.Getenv("TZ"), ":"); tz != "" && tz != "Local" {
+		return zoneName(tz)
+	}
+	if target, err := filepath.EvalSymlinks("/etc/localtime"); err == nil {
+		if tz := zoneName(target); tz != target {
+			return tz
+		}
+	}
+	if data, err := os.ReadFile("/etc/timezone"); err == nil {
+		if tz := strings.TrimSpace(string(data)); tz != "" {
+			return tz
+		}
+	}
+	return "UTC"
+}
+
+// zoneName strips the zoneinfo directory from a path like
+// /usr/share/zoneinfo/Europe/Berlin
+func zoneName(path string) string {
+	const zoneinfo = "zoneinfo/"
+	if i := strings.LastIndex(path, zoneinfo); i >= 0 {
+		return path[i+len(zoneinfo):]
+	}
+	return path
+}
@@ -2,6 +2,8 @@ package database
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
@@ -23,17 +25,55 @@ type AnswerResponse struct {
 	Week      int                `bson:"week"`
 	Question  string             `bson:"question"`
 	Source    string             `bson:"source"`
+	Timezone  string             `bson:"timezone"`
+	// Value is the numeric answer for number, time and duration questions
+	Value *float64 `bson:"value,omitempty"`
+	// Values are the selected options for multiselect questions
+	Values []string `bson:"values,omitempty"`
+	// Attachment is the file sent as the answer, e.g. for photo questions
+	Attachment *Attachment `bson:"attachment,omitempty"`
+	// Note is extra context given with a quick-logged answer
+	Note string `bson:"note,omitempty"`
 }
 
-type PastValues struct {
-	Values  []string
-	Times   []string
-	Minimum int
-	Maximum int
+// Attachment references a file saved in the blob store
+type Attachment struct {
+	Kind     string `bson:"kind"`
+	Path     string `bson:"path"`
+	Caption  string `bson:"caption,omitempty"`
+	Width    int    `bson:"width,omitempty"`
+	Height   int    `bson:"height,omitempty"`
+	Size     int    `bson:"size,omitempty"`
+	Duration int    `bson:"duration,omitempty"`
+	MimeType string `bson:"mimeType,omitempty"`
+	// Transcript is the text of a voice note, if a transcriber is configured
+	Transcript string `bson:"transcript,omitempty"`
 }
 
+// Pause suspends scheduled check-ins for a category, or every category
+// when Category is empty. An Until of 0 means the pause lasts until resumed.
+type Pause struct {
+	Category string `bson:"category"`
+	From     int64  `bson:"from"`
+	Until    int64  `bson:"until"`
+}
+
+// ErrNotFound is returned when a requested setting or answer doesn't exist
+var ErrNotFound = errors.New("not found")
+
 type Database interface {
 	SaveAnswer(context.Context, AnswerResponse) error
-	GetValues(ctx context.Context, key string) (PastValues, error)
+	GetAnswersSince(ctx context.Context, keys []string, since time.Time) ([]AnswerResponse, error)
+	// GetLastAnswer returns the most recent answer for the key,
+	// or ErrNotFound if it was never answered
+	GetLastAnswer(ctx context.Context, key string) (AnswerResponse, error)
+	// GetStreak counts the streaks of true answers for a boolean question
+	GetStreak(ctx context.Context, key string, opts StreakOptions) (Streak, error)
+	// SearchAnswers finds text answers matching the query, newest first
+	SearchAnswers(ctx context.Context, query string, skip int, limit int) ([]AnswerResponse, error)
+	// GetSetting decodes the stored setting into value, returning
+	// ErrNotFound if it has never been saved
+	GetSetting(ctx context.Context, key string, value interface{}) error
+	SaveSetting(ctx context.Context, key string, value interface{}) error
 	// GetAverage(ctx context.Context, key string) error
 }
@@ -2,17 +2,17 @@ package database_test
 
 import (
 	"context"
-	"fmt"
 	"os"
-	"strings"
 	"testing"
+	"time"
 
+	"github.com/imdevinc/mylife/pkg/chart"
 	"github.com/imdevinc/mylife/pkg/database"
 	"github.com/joho/godotenv"
 	"github.com/stretchr/testify/assert"
 )
 
-func TestGetValues(t *testing.T) {
+func TestGetAnswersSince(t *testing.T) {
 	if testing.Short() {
 		t.Skip("this connects to a live database, only run on full tests")
 	}
@@ -27,13 +27,19 @@ func TestGetValues(t *testing.T) {
 	if !assert.NoError(t, err, "expected no error") {
 		t.FailNow()
 	}
-	vals, err := db.GetValues(context.TODO(), "mood")
+	answers, err := db.GetAnswersSince(context.TODO(), []string{"mood"}, time.Time{})
 	if !assert.NoError(t, err, "expected no error") {
 		t.FailNow()
 	}
-	if !assert.Greater(t, len(vals.Values), 0, "expected more results") {
+	if !assert.Greater(t, len(answers), 0, "expected more results") {
 		t.Fail()
 	}
-	url := fmt.Sprintf("https://chart.googleapis.com/chart?cht=lc&chd=t:%s&chs=800x350&chl=%s&chtt=%s&chf=bg,s,e0e0e0&chco=000000,0000FF&chma=30,30,30,30&chds=%d,%d", strings.Join(vals.Values, ","), strings.Join(vals.Times, "%7C"), "mood", vals.Minimum, vals.Maximum)
-	t.Log(url)
+	series := chart.TimeSeries{Name: "mood"}
+	for _, a := range answers {
+		if v, err := database.NumericValue(a); err == nil {
+			series.Times = append(series.Times, a.Timestamp)
+			series.Values = append(series.Values, v)
+		}
+	}
+	t.Log(chart.TimeSeriesURL("mood", time.UTC, series))
 }
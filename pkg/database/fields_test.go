@@ -0,0 +1,161 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPopulateFields(t *testing.T) {
+	tests := []struct {
+		name      string
+		now       time.Time
+		timezone  string
+		year      int
+		month     int
+		day       int
+		hour      int
+		quarter   int
+		week      int
+		yearWeek  int
+		yearMonth int
+	}{
+		{
+			name:      "last day of the year in ISO week 53",
+			now:       time.Date(2020, 12, 31, 23, 30, 0, 0, time.UTC),
+			timezone:  "UTC",
+			year:      2020,
+			month:     12,
+			day:       31,
+			hour:      23,
+			quarter:   4,
+			week:      53,
+			yearWeek:  202053,
+			yearMonth: 202012,
+		},
+		{
+			name:      "new year in the previous ISO week keeps the calendar year",
+			now:       time.Date(2021, 1, 1, 0, 15, 0, 0, time.UTC),
+			timezone:  "UTC",
+			year:      2021,
+			month:     1,
+			day:       1,
+			hour:      0,
+			quarter:   1,
+			week:      53,
+			yearWeek:  202153,
+			yearMonth: 202101,
+		},
+		{
+			name:      "end of december in the next ISO week keeps the calendar year",
+			now:       time.Date(2019, 12, 30, 12, 0, 0, 0, time.UTC),
+			timezone:  "UTC",
+			year:      2019,
+			month:     12,
+			day:       30,
+			hour:      12,
+			quarter:   4,
+			week:      1,
+			yearWeek:  201901,
+			yearMonth: 201912,
+		},
+		{
+			name:      "end of first quarter",
+			now:       time.Date(2022, 3, 31, 23, 59, 0, 0, time.UTC),
+			timezone:  "UTC",
+			year:      2022,
+			month:     3,
+			day:       31,
+			hour:      23,
+			quarter:   1,
+			week:      13,
+			yearWeek:  202213,
+			yearMonth: 202203,
+		},
+		{
+			name:      "start of second quarter",
+			now:       time.Date(2022, 4, 1, 0, 0, 0, 0, time.UTC),
+			timezone:  "UTC",
+			year:      2022,
+			month:     4,
+			day:       1,
+			hour:      0,
+			quarter:   2,
+			week:      13,
+			yearWeek:  202213,
+			yearMonth: 202204,
+		},
+		{
+			name:      "start of third quarter",
+			now:       time.Date(2022, 7, 1, 8, 0, 0, 0, time.UTC),
+			timezone:  "UTC",
+			year:      2022,
+			month:     7,
+			day:       1,
+			hour:      8,
+			quarter:   3,
+			week:      26,
+			yearWeek:  202226,
+			yearMonth: 202207,
+		},
+		{
+			name:      "start of fourth quarter",
+			now:       time.Date(2022, 10, 1, 8, 0, 0, 0, time.UTC),
+			timezone:  "UTC",
+			year:      2022,
+			month:     10,
+			day:       1,
+			hour:      8,
+			quarter:   4,
+			week:      39,
+			yearWeek:  202239,
+			yearMonth: 202210,
+		},
+		{
+			name:      "timezone moves answer into previous year",
+			now:       time.Date(2021, 1, 1, 3, 0, 0, 0, time.UTC),
+			timezone:  "America/Los_Angeles",
+			year:      2020,
+			month:     12,
+			day:       31,
+			hour:      19,
+			quarter:   4,
+			week:      53,
+			yearWeek:  202053,
+			yearMonth: 202012,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			answer := AnswerResponse{Key: "mood", Timezone: tt.timezone}
+			if !assert.NoError(t, populateFields(&answer, tt.now), "expected no error") {
+				t.FailNow()
+			}
+			assert.Equal(t, tt.now.Unix(), answer.Timestamp)
+			assert.Equal(t, tt.timezone, answer.Timezone)
+			assert.Equal(t, tt.year, answer.Year)
+			assert.Equal(t, tt.month, answer.Month)
+			assert.Equal(t, tt.day, answer.Day)
+			assert.Equal(t, tt.hour, answer.Hour)
+			assert.Equal(t, tt.quarter, answer.Quarter)
+			assert.Equal(t, tt.week, answer.Week)
+			assert.Equal(t, tt.yearWeek, answer.YearWeek)
+			assert.Equal(t, tt.yearMonth, answer.YearMonth)
+		})
+	}
+}
+
+func TestPopulateFieldsInvalidTimezone(t *testing.T) {
+	answer := AnswerResponse{Key: "mood", Timezone: "Not/AZone"}
+	assert.Error(t, populateFields(&answer, time.Now()), "expected invalid timezone to fail")
+}
+
+func TestPopulateFieldsTimezone(t *testing.T) {
+	answer := AnswerResponse{Key: "mood"}
+	assert.NoError(t, populateFields(&answer, time.Date(2022, 4, 1, 0, 0, 0, 0, time.UTC)))
+	assert.Equal(t, "UTC", answer.Timezone)
+
+	answer = AnswerResponse{Key: "mood", Timezone: "Local"}
+	assert.Error(t, populateFields(&answer, time.Date(2022, 4, 1, 0, 0, 0, 0, time.UTC)))
+}
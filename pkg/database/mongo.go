@@ -2,20 +2,26 @@ package database
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strconv"
 	"time"
 
+	"github.com/imdevinc/mylife/pkg/clock"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"go.mongodb.org/mongo-driver/mongo/readpref"
+
+	log "github.com/sirupsen/logrus"
 )
 
 type MongoDatabase struct {
 	client     *mongo.Client
 	collection *mongo.Collection
+	settings   *mongo.Collection
+	clock      clock.Clock
 }
 
 type MongoDatabaseOptions struct {
@@ -24,6 +30,8 @@ type MongoDatabaseOptions struct {
 	URL      string
 	Port     string
 	Database string
+	// Clock is used to timestamp answers, defaults to the system time
+	Clock clock.Clock
 }
 
 func NewMongoDB(ctx context.Context, cfg MongoDatabaseOptions) (Database, error) {
@@ -37,11 +45,21 @@ func NewMongoDB(ctx context.Context, cfg MongoDatabaseOptions) (Database, error)
 		return nil, fmt.Errorf("failed to ping database. %v", err)
 	}
 	collection := client.Database(cfg.Database).Collection("answers")
-	return &MongoDatabase{client: client, collection: collection}, nil
+	// Only /search needs the index, e.g. it fails if a different text
+	// index already exists, so the bot still starts without it
+	if _, err := collection.Indexes().CreateOne(ctx, textIndex); err != nil {
+		log.WithError(err).Warn("failed to create text index, searching answers won't work")
+	}
+	settings := client.Database(cfg.Database).Collection("settings")
+	clk := cfg.Clock
+	if clk == nil {
+		clk = clock.Real{}
+	}
+	return &MongoDatabase{client: client, collection: collection, settings: settings, clock: clk}, nil
 }
 
 func (d *MongoDatabase) SaveAnswer(ctx context.Context, msg AnswerResponse) error {
-	if err := populateFields(&msg); err != nil {
+	if err := populateFields(&msg, d.clock.Now()); err != nil {
 		return fmt.Errorf("failed to populate data. %w", err)
 	}
 	if _, err := d.collection.InsertOne(ctx, msg); err != nil {
@@ -54,41 +72,109 @@ func (d *MongoDatabase) Disconnect(ctx context.Context) {
 	d.client.Disconnect(ctx)
 }
 
-func (d *MongoDatabase) GetValues(ctx context.Context, key string) (PastValues, error) {
+// NumericValue returns the numeric value of an answer, either the stored
+// value for number questions or the answer parsed as a number
+func NumericValue(answer AnswerResponse) (float64, error) {
+	if answer.Value != nil {
+		return *answer.Value, nil
+	}
+	val, err := strconv.ParseFloat(answer.Answer, 64)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse answer %s. %v", answer.Answer, err)
+	}
+	return val, nil
+}
+
+// OptionCounts counts how many times each option of a
+// multiselect question was selected
+func OptionCounts(answers []AnswerResponse) map[string]int {
+	counts := map[string]int{}
+	for _, a := range answers {
+		for _, v := range a.Values {
+			counts[v]++
+		}
+	}
+	return counts
+}
+
+func (d *MongoDatabase) GetLastAnswer(ctx context.Context, key string) (AnswerResponse, error) {
 	filter := bson.D{primitive.E{Key: "key", Value: key}}
-	var limit int64 = 300
-	cursor, err := d.collection.Find(ctx, filter, &options.FindOptions{Limit: &limit})
+	opts := options.FindOne().SetSort(bson.D{primitive.E{Key: "timestamp", Value: -1}})
+	result := AnswerResponse{}
+	err := d.collection.FindOne(ctx, filter, opts).Decode(&result)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return AnswerResponse{}, ErrNotFound
+	}
 	if err != nil {
-		return PastValues{}, fmt.Errorf("failed to query database. %v", err)
+		return AnswerResponse{}, fmt.Errorf("failed to query database. %v", err)
+	}
+	return result, nil
+}
+
+func (d *MongoDatabase) GetAnswersSince(ctx context.Context, keys []string, since time.Time) ([]AnswerResponse, error) {
+	filter := bson.D{
+		primitive.E{Key: "key", Value: bson.D{primitive.E{Key: "$in", Value: keys}}},
+		primitive.E{Key: "timestamp", Value: bson.D{primitive.E{Key: "$gte", Value: since.Unix()}}},
+	}
+	cursor, err := d.collection.Find(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query database. %v", err)
 	}
 	results := []AnswerResponse{}
-	err = cursor.All(ctx, &results)
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, fmt.Errorf("failed to marshal database response. %v", err)
+	}
+	return results, nil
+}
+
+func (d *MongoDatabase) GetSetting(ctx context.Context, key string, value interface{}) error {
+	filter := bson.D{primitive.E{Key: "_id", Value: key}}
+	var result struct {
+		Value bson.RawValue `bson:"value"`
+	}
+	err := d.settings.FindOne(ctx, filter).Decode(&result)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return ErrNotFound
+	}
 	if err != nil {
-		return PastValues{}, fmt.Errorf("failed to marshal database response. %v", err)
-	}
-	returnValue := PastValues{}
-	for _, result := range results {
-		returnValue.Values = append(returnValue.Values, result.Answer)
-		t := time.Unix(result.Timestamp, 0).Format("02-01")
-		returnValue.Times = append(returnValue.Times, t)
-		val, err := strconv.Atoi(result.Answer)
-		if err != nil {
-			return PastValues{}, fmt.Errorf("failed to parse answer %s. %v", result.Answer, err)
-		}
-		if val < returnValue.Minimum {
-			returnValue.Minimum = val
-		}
-		if val > returnValue.Maximum {
-			returnValue.Maximum = val
-		}
+		return fmt.Errorf("failed to query setting %s. %w", key, err)
+	}
+	if err := result.Value.Unmarshal(value); err != nil {
+		return fmt.Errorf("failed to unmarshal setting %s. %w", key, err)
+	}
+	return nil
+}
+
+func (d *MongoDatabase) SaveSetting(ctx context.Context, key string, value interface{}) error {
+	filter := bson.D{primitive.E{Key: "_id", Value: key}}
+	doc := bson.D{
+		primitive.E{Key: "_id", Value: key},
+		primitive.E{Key: "value", Value: value},
 	}
-	return returnValue, nil
+	if _, err := d.settings.ReplaceOne(ctx, filter, doc, options.Replace().SetUpsert(true)); err != nil {
+		return fmt.Errorf("failed to save setting %s. %w", key, err)
+	}
+	return nil
 }
 
-func populateFields(answer *AnswerResponse) error {
+// populateFields derives the date fields of the answer from now,
+// in the answer's timezone
+func populateFields(answer *AnswerResponse, now time.Time) error {
 	answer.ID = primitive.NewObjectID()
-	ts := time.Now()
+	if answer.Timezone == "" {
+		answer.Timezone = "UTC"
+	}
+	// "Local" depends on the host, so it can't be interpreted later
+	if answer.Timezone == "Local" {
+		return fmt.Errorf("timezone must be an IANA name like Europe/Berlin, not Local")
+	}
+	loc, err := time.LoadLocation(answer.Timezone)
+	if err != nil {
+		return fmt.Errorf("failed to load timezone %s. %w", answer.Timezone, err)
+	}
+	ts := now.In(loc)
 	answer.Timestamp = ts.Unix()
+	answer.Timezone = loc.String()
 
 	answer.Day = ts.Day()
 	answer.Hour = ts.Hour()
@@ -0,0 +1,49 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// searchType is the only question type whose answers are searched
+const searchType string = "text"
+
+// textIndex indexes the answers and notes for SearchAnswers
+var textIndex = mongo.IndexModel{
+	Keys: bson.D{
+		primitive.E{Key: "answer", Value: "text"},
+		primitive.E{Key: "note", Value: "text"},
+	},
+	Options: options.Index().SetName("answer_text"),
+}
+
+func (d *MongoDatabase) SearchAnswers(ctx context.Context, query string, skip int, limit int) ([]AnswerResponse, error) {
+	filter := bson.D{
+		primitive.E{Key: "$text", Value: bson.D{primitive.E{Key: "$search", Value: query}}},
+		primitive.E{Key: "type", Value: searchType},
+	}
+	opts := options.Find().
+		SetSort(bson.D{primitive.E{Key: "timestamp", Value: -1}}).
+		SetSkip(int64(skip)).
+		SetLimit(int64(limit))
+	cursor, err := d.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query database. %v", err)
+	}
+	results := []AnswerResponse{}
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, fmt.Errorf("failed to marshal database response. %v", err)
+	}
+	return results, nil
+}
+
+// SearchTerms splits a search query into lowercase terms
+func SearchTerms(query string) []string {
+	return strings.Fields(strings.ToLower(query))
+}
@@ -0,0 +1,106 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Streak is the number of consecutive days, or weeks, a boolean
+// question was answered with true
+type Streak struct {
+	Current int
+	Longest int
+}
+
+// StreakOptions describe how streaks are counted for a question
+type StreakOptions struct {
+	// Weekly counts ISO weeks instead of days
+	Weekly bool
+	// Pauses that apply to the question. Paused days don't break a streak.
+	Pauses []Pause
+	// Now is the end of the streak, its location decides where days start
+	Now time.Time
+}
+
+func (d *MongoDatabase) GetStreak(ctx context.Context, key string, opts StreakOptions) (Streak, error) {
+	filter := bson.D{
+		primitive.E{Key: "key", Value: key},
+		primitive.E{Key: "answer", Value: "true"},
+	}
+	cursor, err := d.collection.Find(ctx, filter)
+	if err != nil {
+		return Streak{}, fmt.Errorf("failed to query database. %v", err)
+	}
+	results := []AnswerResponse{}
+	if err := cursor.All(ctx, &results); err != nil {
+		return Streak{}, fmt.Errorf("failed to marshal database response. %v", err)
+	}
+	return ComputeStreak(results, opts), nil
+}
+
+// ComputeStreak counts the current and longest streaks of true answers.
+// The current streak isn't broken until the current day or week is over,
+// so it may end in the previous one.
+func ComputeStreak(answers []AnswerResponse, opts StreakOptions) Streak {
+	loc := opts.Now.Location()
+	step := func(t time.Time, n int) time.Time { return t.AddDate(0, 0, n) }
+	if opts.Weekly {
+		step = func(t time.Time, n int) time.Time { return t.AddDate(0, 0, 7*n) }
+	}
+	start := func(t time.Time) time.Time {
+		day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+		if opts.Weekly {
+			// ISO weeks start on Monday
+			day = day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+		}
+		return day
+	}
+
+	done := map[int64]bool{}
+	var first time.Time
+	for _, a := range answers {
+		if a.Answer != "true" {
+			continue
+		}
+		period := start(time.Date(a.Year, time.Month(a.Month), a.Day, 0, 0, 0, 0, loc))
+		done[period.Unix()] = true
+		if first.IsZero() || period.Before(first) {
+			first = period
+		}
+	}
+	if first.IsZero() {
+		return Streak{}
+	}
+	paused := func(period time.Time) bool {
+		from, until := period.Unix(), step(period, 1).Unix()
+		for _, p := range opts.Pauses {
+			if p.From < until && (p.Until == 0 || p.Until > from) {
+				return true
+			}
+		}
+		return false
+	}
+
+	current := start(opts.Now)
+	streak := Streak{}
+	run := 0
+	for period := first; !period.After(current); period = step(period, 1) {
+		switch {
+		case done[period.Unix()]:
+			run++
+		case period.Equal(current), paused(period):
+			// The current period can still be answered and paused ones are skipped
+		default:
+			run = 0
+		}
+		if run > streak.Longest {
+			streak.Longest = run
+		}
+	}
+	streak.Current = run
+	return streak
+}
@@ -0,0 +1,71 @@
+package database_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/imdevinc/mylife/pkg/database"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComputeStreak(t *testing.T) {
+	now := time.Date(2023, 1, 4, 20, 0, 0, 0, time.UTC)
+	answer := func(year int, month int, day int, value string) database.AnswerResponse {
+		return database.AnswerResponse{Key: "workout", Year: year, Month: month, Day: day, Answer: value}
+	}
+	day := func(month time.Month, day int) int64 {
+		return time.Date(2023, month, day, 0, 0, 0, 0, time.UTC).Unix()
+	}
+	tests := []struct {
+		name    string
+		answers []database.AnswerResponse
+		opts    database.StreakOptions
+		want    database.Streak
+	}{
+		{name: "no answers", want: database.Streak{}},
+		{
+			name:    "across the new year",
+			answers: []database.AnswerResponse{answer(2022, 12, 31, "true"), answer(2023, 1, 1, "true"), answer(2023, 1, 2, "true"), answer(2023, 1, 3, "true"), answer(2023, 1, 4, "true")},
+			want:    database.Streak{Current: 5, Longest: 5},
+		},
+		{
+			name:    "not answered yet today",
+			answers: []database.AnswerResponse{answer(2023, 1, 2, "true"), answer(2023, 1, 3, "true")},
+			want:    database.Streak{Current: 2, Longest: 2},
+		},
+		{
+			name:    "broken by a missed day",
+			answers: []database.AnswerResponse{answer(2022, 12, 28, "true"), answer(2022, 12, 29, "true"), answer(2022, 12, 30, "true"), answer(2023, 1, 3, "true"), answer(2023, 1, 4, "true")},
+			want:    database.Streak{Current: 2, Longest: 3},
+		},
+		{
+			name:    "false answers don't count",
+			answers: []database.AnswerResponse{answer(2023, 1, 3, "true"), answer(2023, 1, 4, "false")},
+			want:    database.Streak{Current: 1, Longest: 1},
+		},
+		{
+			name:    "paused days don't break the streak",
+			answers: []database.AnswerResponse{answer(2022, 12, 30, "true"), answer(2023, 1, 3, "true")},
+			opts:    database.StreakOptions{Pauses: []database.Pause{{From: day(1, 1) - 3600, Until: day(1, 2) + 3600}}},
+			want:    database.Streak{Current: 2, Longest: 2},
+		},
+		{
+			name:    "weekly",
+			answers: []database.AnswerResponse{answer(2022, 12, 20, "true"), answer(2022, 12, 26, "true"), answer(2023, 1, 2, "true")},
+			opts:    database.StreakOptions{Weekly: true},
+			want:    database.Streak{Current: 3, Longest: 3},
+		},
+		{
+			name:    "weekly with a missed week",
+			answers: []database.AnswerResponse{answer(2022, 12, 12, "true"), answer(2022, 12, 28, "true")},
+			opts:    database.StreakOptions{Weekly: true},
+			want:    database.Streak{Current: 1, Longest: 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.Now = now
+			assert.Equal(t, tt.want, database.ComputeStreak(tt.answers, tt.opts))
+		})
+	}
+}
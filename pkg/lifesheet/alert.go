@@ -0,0 +1,76 @@
+package lifesheet
+
+import (
+	"fmt"
+	"time"
+)
+
+// Alert is a rule checked against a question's recent answers. It fires
+// when the daily average is below or above a threshold on each of the
+// last Days days, or when Missing is set and the question wasn't answered
+// (or a boolean wasn't true) in the last Days days.
+type Alert struct {
+	Below   *float64 `json:"below" yaml:"below"`
+	Above   *float64 `json:"above" yaml:"above"`
+	Missing bool     `json:"missing" yaml:"missing"`
+	Days    int      `json:"days" yaml:"days"`
+	// Message is sent when the alert fires, a description of the rule
+	// is sent if it's empty
+	Message string `json:"message" yaml:"message"`
+	// FollowUp is a category asked after the message
+	FollowUp string `json:"followUp" yaml:"followUp"`
+	// Cooldown is how long to wait before firing again (e.g. "72h"),
+	// it defaults to the alert's number of days
+	Cooldown string `json:"cooldown" yaml:"cooldown"`
+}
+
+// Text returns the message to send when the alert fires
+func (a Alert) Text(key string) string {
+	if a.Message != "" {
+		return a.Message
+	}
+	switch {
+	case a.Below != nil:
+		return fmt.Sprintf("%s has been below %s for %d days in a row", key, FormatNumber(*a.Below), a.Days)
+	case a.Above != nil:
+		return fmt.Sprintf("%s has been above %s for %d days in a row", key, FormatNumber(*a.Above), a.Days)
+	}
+	return fmt.Sprintf("No %s for %d days", key, a.Days)
+}
+
+// CooldownDuration returns how long the alert stays quiet after firing
+func (a Alert) CooldownDuration() time.Duration {
+	if d, err := time.ParseDuration(a.Cooldown); err == nil && d > 0 {
+		return d
+	}
+	return time.Duration(a.Days) * 24 * time.Hour
+}
+
+// validate checks the alert of a question at the given path
+func (a Alert) validate(q Question, categories map[string]Category, path string, add func(string, string, ...interface{})) {
+	rules := 0
+	for _, set := range []bool{a.Below != nil, a.Above != nil, a.Missing} {
+		if set {
+			rules++
+		}
+	}
+	if rules != 1 {
+		add(path, "alert needs exactly one of below, above or missing")
+	}
+	if (a.Below != nil || a.Above != nil) && q.Type != TypeNumber && q.Type != TypeRange {
+		add(path, "below and above are only used with number and range questions")
+	}
+	if a.Days <= 0 {
+		add(path+".days", "days must be at least 1")
+	}
+	if a.FollowUp != "" {
+		if _, ok := categories[a.FollowUp]; !ok {
+			add(path+".followUp", "unknown category %q", a.FollowUp)
+		}
+	}
+	if a.Cooldown != "" {
+		if d, err := time.ParseDuration(a.Cooldown); err != nil || d <= 0 {
+			add(path+".cooldown", "invalid duration %q", a.Cooldown)
+		}
+	}
+}
@@ -0,0 +1,223 @@
+package lifesheet
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	clockTime      = regexp.MustCompile(`^(\d{1,2})(?::?(\d{2}))?\s*(am|pm|a|p)?$`)
+	hourMinutes    = regexp.MustCompile(`^(\d+):(\d{2})$`)
+	trailingMinute = regexp.MustCompile(`h(\d+)$`)
+	durationUnits  = []struct {
+		pattern *regexp.Regexp
+		unit    string
+	}{
+		{regexp.MustCompile(`hours?|hrs?`), "h"},
+		{regexp.MustCompile(`minutes?|mins?`), "m"},
+		{regexp.MustCompile(`seconds?|secs?`), "s"},
+	}
+)
+
+// ParsedAnswer is an answer normalized according to its question type
+type ParsedAnswer struct {
+	// Text is what gets stored as the answer
+	Text string
+	// Value is set for questions that have a numeric answer
+	Value *float64
+	// Values is set for multiselect questions
+	Values []string
+}
+
+// ParseAnswer validates the raw answer against the question type and
+// normalizes it. The error is meant to be shown to the user.
+func (q Question) ParseAnswer(text string) (ParsedAnswer, error) {
+	text = strings.TrimSpace(text)
+	switch q.Type {
+	case TypeNumber:
+		v, err := q.parseNumber(text)
+		if err != nil {
+			return ParsedAnswer{}, err
+		}
+		return ParsedAnswer{Text: FormatNumber(v), Value: &v}, nil
+	case TypeTime:
+		minutes, err := parseTimeOfDay(text)
+		if err != nil {
+			return ParsedAnswer{}, err
+		}
+		v := float64(minutes)
+		return ParsedAnswer{Text: FormatTimeOfDay(minutes), Value: &v}, nil
+	case TypeDuration:
+		d, err := parseDuration(text)
+		if err != nil {
+			return ParsedAnswer{}, err
+		}
+		v := d.Seconds()
+		return ParsedAnswer{Text: FormatDuration(d), Value: &v}, nil
+	case TypeMultiselect:
+		values, err := q.parseOptions(text)
+		if err != nil {
+			return ParsedAnswer{}, err
+		}
+		return ParsedAnswer{Text: strings.Join(values, ","), Values: values}, nil
+	}
+	return ParsedAnswer{Text: text}, nil
+}
+
+// parseOptions accepts a comma separated list of option keys or labels
+// and returns the sorted, unique option keys
+func (q Question) parseOptions(text string) ([]string, error) {
+	selected := map[string]bool{}
+	for _, raw := range strings.Split(text, ",") {
+		raw = strings.TrimSpace(raw)
+		if raw == "" {
+			continue
+		}
+		found := false
+		for k, label := range q.Buttons {
+			if strings.EqualFold(raw, k) || strings.EqualFold(raw, label) {
+				selected[k] = true
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown option %s, tap the buttons or send a comma separated list", raw)
+		}
+	}
+	values := []string{}
+	for k := range selected {
+		values = append(values, k)
+	}
+	sort.Strings(values)
+	return values, nil
+}
+
+// parseTimeOfDay accepts times like "23:40", "2340", "11:40pm" or "7am"
+// and returns the minutes since midnight
+func parseTimeOfDay(text string) (int, error) {
+	invalid := fmt.Errorf("please answer with a time like 23:40 or 11:40pm")
+	m := clockTime.FindStringSubmatch(strings.ToLower(strings.ReplaceAll(text, ".", "")))
+	if m == nil {
+		return 0, invalid
+	}
+	hour, _ := strconv.Atoi(m[1])
+	minute := 0
+	if m[2] != "" {
+		minute, _ = strconv.Atoi(m[2])
+	}
+	if minute > 59 {
+		return 0, invalid
+	}
+	switch m[3] {
+	case "":
+		if hour > 23 {
+			return 0, invalid
+		}
+	default:
+		if hour < 1 || hour > 12 {
+			return 0, invalid
+		}
+		hour = hour % 12
+		if strings.HasPrefix(m[3], "p") {
+			hour += 12
+		}
+	}
+	return hour*60 + minute, nil
+}
+
+// parseDuration accepts durations like "1h30", "90m", "90 min", "1:30" or
+// a bare number of minutes
+func parseDuration(text string) (time.Duration, error) {
+	invalid := fmt.Errorf("please answer with a duration like 1h30 or 90m")
+	raw := strings.ReplaceAll(strings.ToLower(text), " ", "")
+	if raw == "" {
+		return 0, invalid
+	}
+	if _, err := strconv.ParseFloat(raw, 64); err == nil {
+		raw += "m"
+	} else if m := hourMinutes.FindStringSubmatch(raw); m != nil {
+		raw = fmt.Sprintf("%sh%sm", m[1], m[2])
+	}
+	for _, u := range durationUnits {
+		raw = u.pattern.ReplaceAllString(raw, u.unit)
+	}
+	raw = trailingMinute.ReplaceAllString(raw, "h${1}m")
+	d, err := time.ParseDuration(raw)
+	if err != nil || d < 0 {
+		return 0, invalid
+	}
+	return d, nil
+}
+
+// FormatTimeOfDay formats minutes since midnight as HH:MM
+func FormatTimeOfDay(minutes int) string {
+	return fmt.Sprintf("%02d:%02d", minutes/60, minutes%60)
+}
+
+// FormatDuration formats a duration as hours and minutes, e.g. 1h30m
+func FormatDuration(d time.Duration) string {
+	d = d.Round(time.Second)
+	if d < time.Minute {
+		return d.String()
+	}
+	hours := int(d / time.Hour)
+	minutes := int(d % time.Hour / time.Minute)
+	seconds := int(d % time.Minute / time.Second)
+	out := ""
+	if hours > 0 {
+		out += fmt.Sprintf("%dh", hours)
+	}
+	if minutes > 0 {
+		out += fmt.Sprintf("%dm", minutes)
+	}
+	if seconds > 0 {
+		out += fmt.Sprintf("%ds", seconds)
+	}
+	return out
+}
+
+// parseNumber accepts numbers like "72.5", "72,5" or "72.5kg" and
+// checks them against the question's bounds
+func (q Question) parseNumber(text string) (float64, error) {
+	raw := strings.TrimSpace(strings.TrimSuffix(strings.ToLower(text), strings.ToLower(q.Unit)))
+	raw = strings.Replace(raw, ",", ".", 1)
+	v, err := strconv.ParseFloat(raw, 64)
+	if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0, fmt.Errorf("please answer with a number%s", q.numberHint())
+	}
+	if q.Decimals != nil {
+		pow := math.Pow(10, float64(*q.Decimals))
+		v = math.Round(v*pow) / pow
+	}
+	if (q.Min != nil && v < *q.Min) || (q.Max != nil && v > *q.Max) {
+		return 0, fmt.Errorf("%s is out of range, please answer with a number%s", FormatNumber(v), q.numberHint())
+	}
+	return v, nil
+}
+
+// numberHint describes the expected bounds and unit of a number question
+func (q Question) numberHint() string {
+	hint := ""
+	if q.Min != nil && q.Max != nil {
+		hint = fmt.Sprintf(" between %s and %s", FormatNumber(*q.Min), FormatNumber(*q.Max))
+	} else if q.Min != nil {
+		hint = fmt.Sprintf(" of at least %s", FormatNumber(*q.Min))
+	} else if q.Max != nil {
+		hint = fmt.Sprintf(" of at most %s", FormatNumber(*q.Max))
+	}
+	if q.Unit != "" {
+		hint = fmt.Sprintf("%s (%s)", hint, q.Unit)
+	}
+	return hint
+}
+
+// FormatNumber formats a number without trailing zeros
+func FormatNumber(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
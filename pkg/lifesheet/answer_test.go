@@ -0,0 +1,74 @@
+package lifesheet_test
+
+import (
+	"testing"
+
+	"github.com/imdevinc/mylife/pkg/lifesheet"
+	"github.com/stretchr/testify/assert"
+)
+
+func float(v float64) *float64 {
+	return &v
+}
+
+func integer(v int) *int {
+	return &v
+}
+
+func TestParseAnswer(t *testing.T) {
+	weight := lifesheet.Question{Key: "weight", Type: "number", Unit: "kg", Min: float(30), Max: float(300), Decimals: integer(1)}
+	bedtime := lifesheet.Question{Key: "bedtime", Type: "time"}
+	meditation := lifesheet.Question{Key: "meditation", Type: "duration"}
+	activities := lifesheet.Question{Key: "activities", Type: "multiselect", Buttons: map[string]string{"walk": "Went for a walk", "gym": "Gym"}}
+	tests := []struct {
+		name     string
+		question lifesheet.Question
+		text     string
+		want     string
+		value    *float64
+		values   []string
+		wantErr  bool
+	}{
+		{name: "text is kept", question: lifesheet.Question{Type: "text"}, text: " grateful ", want: "grateful"},
+		{name: "number", question: weight, text: "72.5", want: "72.5", value: float(72.5)},
+		{name: "number with unit", question: weight, text: "72.5 kg", want: "72.5", value: float(72.5)},
+		{name: "number with comma", question: weight, text: "72,5", want: "72.5", value: float(72.5)},
+		{name: "number is rounded", question: weight, text: "72.46", want: "72.5", value: float(72.5)},
+		{name: "number below min", question: weight, text: "12", wantErr: true},
+		{name: "number above max", question: weight, text: "400", wantErr: true},
+		{name: "not a number", question: weight, text: "heavy", wantErr: true},
+		{name: "24 hour time", question: bedtime, text: "23:40", want: "23:40", value: float(23*60 + 40)},
+		{name: "time without colon", question: bedtime, text: "2340", want: "23:40", value: float(23*60 + 40)},
+		{name: "12 hour time", question: bedtime, text: "11:40pm", want: "23:40", value: float(23*60 + 40)},
+		{name: "12 hour time with space", question: bedtime, text: "11:40 p.m.", want: "23:40", value: float(23*60 + 40)},
+		{name: "midnight", question: bedtime, text: "12am", want: "00:00", value: float(0)},
+		{name: "noon", question: bedtime, text: "12:15pm", want: "12:15", value: float(12*60 + 15)},
+		{name: "after midnight", question: bedtime, text: "00:30", want: "00:30", value: float(30)},
+		{name: "invalid hour", question: bedtime, text: "25:00", wantErr: true},
+		{name: "invalid 12 hour time", question: bedtime, text: "13pm", wantErr: true},
+		{name: "hours and minutes", question: meditation, text: "1h30", want: "1h30m", value: float(5400)},
+		{name: "minutes", question: meditation, text: "90m", want: "1h30m", value: float(5400)},
+		{name: "minutes with unit name", question: meditation, text: "45 min", want: "45m", value: float(2700)},
+		{name: "bare minutes", question: meditation, text: "20", want: "20m", value: float(1200)},
+		{name: "clock duration", question: meditation, text: "1:05", want: "1h5m", value: float(3900)},
+		{name: "fractional hours", question: meditation, text: "1.5 hours", want: "1h30m", value: float(5400)},
+		{name: "invalid duration", question: meditation, text: "a while", wantErr: true},
+		{name: "selected options", question: activities, text: "walk,gym", want: "gym,walk", values: []string{"gym", "walk"}},
+		{name: "options by label", question: activities, text: "Went for a walk, gym, gym", want: "gym,walk", values: []string{"gym", "walk"}},
+		{name: "no options", question: activities, text: "", want: "", values: []string{}},
+		{name: "unknown option", question: activities, text: "swim", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.question.ParseAnswer(tt.text)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got.Text)
+			assert.Equal(t, tt.value, got.Value)
+			assert.Equal(t, tt.values, got.Values)
+		})
+	}
+}
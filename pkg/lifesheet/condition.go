@@ -0,0 +1,112 @@
+package lifesheet
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var comparisonExpr = regexp.MustCompile(`^\s*([A-Za-z0-9_\-]+)\s*(==|!=|<=|>=|<|>|\scontains\s)\s*(.+?)\s*$`)
+
+// Condition is a parsed `when` expression. It is a list of comparisons
+// joined by && and ||, where && binds tighter than ||.
+// For example: `workout == true || mood <= 2`
+type Condition struct {
+	any [][]comparison
+}
+
+type comparison struct {
+	key   string
+	op    string
+	value string
+}
+
+// ParseCondition parses a `when` expression
+func ParseCondition(expr string) (Condition, error) {
+	c := Condition{}
+	for _, or := range strings.Split(expr, "||") {
+		all := []comparison{}
+		for _, and := range strings.Split(or, "&&") {
+			m := comparisonExpr.FindStringSubmatch(and)
+			if m == nil {
+				return Condition{}, fmt.Errorf("invalid comparison %q, expected something like `mood <= 2`", strings.TrimSpace(and))
+			}
+			value := strings.Trim(m[3], `"'`)
+			all = append(all, comparison{key: m[1], op: strings.TrimSpace(m[2]), value: value})
+		}
+		c.any = append(c.any, all)
+	}
+	return c, nil
+}
+
+// Keys returns the question keys the condition depends on
+func (c Condition) Keys() []string {
+	keys := []string{}
+	for _, all := range c.any {
+		for _, cmp := range all {
+			keys = append(keys, cmp.key)
+		}
+	}
+	return keys
+}
+
+// Evaluate checks the condition against the answers given so far.
+// Comparisons against questions that weren't answered are false.
+func (c Condition) Evaluate(answers map[string]string) bool {
+	for _, all := range c.any {
+		matched := true
+		for _, cmp := range all {
+			if !cmp.evaluate(answers) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+func (cmp comparison) evaluate(answers map[string]string) bool {
+	answer, ok := answers[cmp.key]
+	if !ok {
+		return false
+	}
+	if cmp.op == "contains" {
+		for _, v := range strings.Split(answer, ",") {
+			if strings.EqualFold(v, cmp.value) {
+				return true
+			}
+		}
+		return false
+	}
+	a, aErr := strconv.ParseFloat(answer, 64)
+	b, bErr := strconv.ParseFloat(cmp.value, 64)
+	if aErr == nil && bErr == nil {
+		switch cmp.op {
+		case "==":
+			return a == b
+		case "!=":
+			return a != b
+		case "<":
+			return a < b
+		case "<=":
+			return a <= b
+		case ">":
+			return a > b
+		case ">=":
+			return a >= b
+		}
+		return false
+	}
+	switch cmp.op {
+	case "==":
+		return strings.EqualFold(answer, cmp.value)
+	case "!=":
+		return !strings.EqualFold(answer, cmp.value)
+	}
+	// Ordering only makes sense for numbers
+	return false
+}
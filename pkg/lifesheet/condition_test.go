@@ -0,0 +1,53 @@
+package lifesheet_test
+
+import (
+	"testing"
+
+	"github.com/imdevinc/mylife/pkg/lifesheet"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCondition(t *testing.T) {
+	answers := map[string]string{
+		"workout":    "true",
+		"mood":       "2",
+		"activities": "gym,walk",
+	}
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{expr: "workout == true", want: true},
+		{expr: "workout == false", want: false},
+		{expr: "workout != false", want: true},
+		{expr: "mood <= 2", want: true},
+		{expr: "mood < 2", want: false},
+		{expr: "mood>=3", want: false},
+		{expr: "mood == 2.0", want: true},
+		{expr: `workout == "true"`, want: true},
+		{expr: "activities contains walk", want: true},
+		{expr: "activities contains swim", want: false},
+		{expr: "mood <= 2 && workout == false", want: false},
+		{expr: "mood > 4 || workout == true", want: true},
+		{expr: "sleep > 8", want: false},
+		{expr: "sleep != 8", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			cond, err := lifesheet.ParseCondition(tt.expr)
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+			assert.Equal(t, tt.want, cond.Evaluate(answers))
+		})
+	}
+}
+
+func TestParseConditionInvalid(t *testing.T) {
+	for _, expr := range []string{"", "mood", "mood ~ 2", "== 2", "mood <= 2 &&"} {
+		t.Run(expr, func(t *testing.T) {
+			_, err := lifesheet.ParseCondition(expr)
+			assert.Error(t, err)
+		})
+	}
+}
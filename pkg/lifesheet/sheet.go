@@ -4,6 +4,37 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Question types
+const (
+	TypeHeader   = "header"
+	TypeText     = "text"
+	TypeRange    = "range"
+	TypeBoolean  = "boolean"
+	TypeLocation = "location"
+	TypeNumber   = "number"
+	// TypeTime answers are stored as minutes since midnight
+	TypeTime = "time"
+	// TypeDuration answers are stored as seconds
+	TypeDuration = "duration"
+	// TypeMultiselect answers are a list of the selected button keys
+	TypeMultiselect = "multiselect"
+	// TypePhoto answers are stored in the blob store, the caption is the answer
+	TypePhoto = "photo"
+)
+
+// Schedules
+const (
+	ScheduleDaily         = "daily"
+	ScheduleWeekly        = "weekly"
+	ScheduleFiveTimesADay = "fiveTimesADay"
+	ScheduleSpecific      = "specific"
 )
 
 type Lifesheet struct {
@@ -11,29 +42,225 @@ type Lifesheet struct {
 }
 
 type Category struct {
-	Description string     `json:"description"`
-	Schedule    string     `json:"schedule"`
-	Questions   []Question `json:"questions"`
-	Times       []string   `json:"times"`
+	Description string     `json:"description" yaml:"description"`
+	Schedule    string     `json:"schedule" yaml:"schedule"`
+	Questions   []Question `json:"questions" yaml:"questions"`
+	Times       []string   `json:"times" yaml:"times"`
+	// SkipIfAnsweredWithin is a duration (e.g. "30m") used by scheduled
+	// check-ins to skip questions that were recently answered
+	SkipIfAnsweredWithin string `json:"skipIfAnsweredWithin" yaml:"skipIfAnsweredWithin"`
 }
 
 type Question struct {
-	Key     string            `json:"key"`
-	Text    string            `json:"question"`
-	Type    string            `json:"type"`
-	Buttons map[string]string `json:"buttons"`
-	Replies map[string]string `json:"replies"`
+	Key     string            `json:"key" yaml:"key"`
+	Text    string            `json:"question" yaml:"question"`
+	Type    string            `json:"type" yaml:"type"`
+	Buttons map[string]string `json:"buttons" yaml:"buttons"`
+	Replies map[string]string `json:"replies" yaml:"replies"`
+	// Unit, Min, Max and Decimals describe number questions
+	Unit     string   `json:"unit" yaml:"unit"`
+	Min      *float64 `json:"min" yaml:"min"`
+	Max      *float64 `json:"max" yaml:"max"`
+	Decimals *int     `json:"decimals" yaml:"decimals"`
+	// SameAsLast adds a button to repeat the previous answer
+	SameAsLast bool `json:"sameAsLast" yaml:"sameAsLast"`
+	// When is a condition on earlier answers in the same check-in,
+	// the question is only asked if it's true. See ParseCondition.
+	When string `json:"when" yaml:"when"`
+	// Alerts are checked after every answer and once a day
+	Alerts []Alert `json:"alerts" yaml:"alerts"`
+}
+
+// Question finds the question with the given key in any category
+func (l *Lifesheet) Question(key string) (Question, bool) {
+	for _, c := range l.Categories {
+		for _, q := range c.Questions {
+			if q.Key != "" && q.Key == key {
+				return q, true
+			}
+		}
+	}
+	return Question{}, false
+}
+
+// QuestionFold finds the question with the given key ignoring case,
+// as commands are typed in any case
+func (l *Lifesheet) QuestionFold(key string) (Question, bool) {
+	for _, c := range l.Categories {
+		for _, q := range c.Questions {
+			if q.Key != "" && strings.EqualFold(q.Key, key) {
+				return q, true
+			}
+		}
+	}
+	return Question{}, false
 }
 
+// includeKey is the top level key listing other lifesheet files or
+// directories to merge in, relative to the file including them
+const includeKey = "include"
+
+// Load reads a lifesheet from a file, or merges every JSON and YAML
+// file in a directory
+func Load(path string) (*Lifesheet, error) {
+	l := &Lifesheet{Categories: map[string]Category{}}
+	if err := l.load(path, map[string]string{}, map[string]bool{}); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// LoadFromFile reads a lifesheet from a single JSON or YAML file,
+// along with any files it includes
 func LoadFromFile(file string) (*Lifesheet, error) {
+	l := &Lifesheet{Categories: map[string]Category{}}
+	if err := l.loadFile(file, map[string]string{}, map[string]bool{}); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// load merges the file or directory into the lifesheet. sources tracks
+// which file each category came from and seen prevents include loops.
+func (l *Lifesheet) load(path string, sources map[string]string, seen map[string]bool) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("failed to read file. %v", err)
+	}
+	if !info.IsDir() {
+		return l.loadFile(path, sources, seen)
+	}
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return fmt.Errorf("failed to read directory. %v", err)
+	}
+	files := []string{}
+	for _, e := range entries {
+		if !e.IsDir() && isSheetFile(e.Name()) {
+			files = append(files, filepath.Join(path, e.Name()))
+		}
+	}
+	sort.Strings(files)
+	for _, f := range files {
+		if err := l.loadFile(f, sources, seen); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (l *Lifesheet) loadFile(file string, sources map[string]string, seen map[string]bool) error {
+	abs, err := filepath.Abs(file)
+	if err != nil {
+		return fmt.Errorf("failed to resolve %s. %v", file, err)
+	}
+	if seen[abs] {
+		return nil
+	}
+	seen[abs] = true
 	data, err := os.ReadFile(file)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read file. %v", err)
+		return fmt.Errorf("failed to read file. %v", err)
 	}
-	var sheet map[string]Category
-	err = json.Unmarshal(data, &sheet)
+	categories, includes, err := decode(file, data)
 	if err != nil {
-		return nil, fmt.Errorf("failed to unmarshal sheet. %v", err)
+		return fmt.Errorf("failed to unmarshal sheet %s. %v", file, err)
+	}
+	if err := l.merge(file, categories, sources); err != nil {
+		return err
+	}
+	for _, inc := range includes {
+		if !filepath.IsAbs(inc) {
+			inc = filepath.Join(filepath.Dir(file), inc)
+		}
+		if err := l.load(inc, sources, seen); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// merge adds the categories from file to the lifesheet, making sure
+// category names and question keys are only defined once
+func (l *Lifesheet) merge(file string, categories map[string]Category, sources map[string]string) error {
+	keys := map[string]string{}
+	for name, c := range l.Categories {
+		for _, q := range c.Questions {
+			if q.Key != "" {
+				keys[q.Key] = sources[name]
+			}
+		}
+	}
+	names := []string{}
+	for name := range categories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if other, ok := sources[name]; ok {
+			return fmt.Errorf("duplicate category %q in %s and %s", name, other, file)
+		}
+		for _, q := range categories[name].Questions {
+			if other, ok := keys[q.Key]; ok && q.Key != "" && other != file {
+				return fmt.Errorf("duplicate question key %q in %s and %s", q.Key, other, file)
+			}
+		}
+		sources[name] = file
+		l.Categories[name] = categories[name]
+	}
+	return nil
+}
+
+func isSheetFile(name string) bool {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".json", ".yaml", ".yml":
+		return true
+	}
+	return false
+}
+
+// decode parses the categories and include list out of a JSON
+// or YAML lifesheet based on the file extension
+func decode(file string, data []byte) (map[string]Category, []string, error) {
+	categories := map[string]Category{}
+	includes := []string{}
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".yaml", ".yml":
+		raw := map[string]yaml.Node{}
+		if err := yaml.Unmarshal(data, &raw); err != nil {
+			return nil, nil, err
+		}
+		for k, v := range raw {
+			var err error
+			if k == includeKey {
+				err = v.Decode(&includes)
+			} else {
+				var c Category
+				err = v.Decode(&c)
+				categories[k] = c
+			}
+			if err != nil {
+				return nil, nil, fmt.Errorf("%s: %v", k, err)
+			}
+		}
+	default:
+		raw := map[string]json.RawMessage{}
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return nil, nil, err
+		}
+		for k, v := range raw {
+			var err error
+			if k == includeKey {
+				err = json.Unmarshal(v, &includes)
+			} else {
+				var c Category
+				err = json.Unmarshal(v, &c)
+				categories[k] = c
+			}
+			if err != nil {
+				return nil, nil, fmt.Errorf("%s: %v", k, err)
+			}
+		}
 	}
-	return &Lifesheet{Categories: sheet}, nil
+	return categories, includes, nil
 }
@@ -0,0 +1,112 @@
+package lifesheet_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/imdevinc/mylife/pkg/lifesheet"
+	"github.com/stretchr/testify/assert"
+)
+
+const moodYAML = `mood:
+  description: Track my current mood during the day
+  schedule: specific
+  times: ["12:00", "17:00"]
+  questions:
+    - key: mood
+      question: How are you feeling today?
+      type: range
+      buttons:
+        "5": happy
+        "0": sad
+`
+
+const asleepJSON = `{
+	"asleep": {
+		"schedule": "daily",
+		"questions": [{"key": "workout", "question": "Did you workout today?", "type": "boolean"}]
+	}
+}`
+
+func writeFile(t *testing.T, dir string, name string, data string) string {
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadYAML(t *testing.T) {
+	dir := t.TempDir()
+	sheet, err := lifesheet.Load(writeFile(t, dir, "mood.yaml", moodYAML))
+	if !assert.NoError(t, err, "expected no error") {
+		t.FailNow()
+	}
+	mood := sheet.Categories["mood"]
+	assert.Equal(t, "specific", mood.Schedule)
+	assert.Equal(t, []string{"12:00", "17:00"}, mood.Times)
+	if assert.Len(t, mood.Questions, 1) {
+		assert.Equal(t, "How are you feeling today?", mood.Questions[0].Text)
+		assert.Equal(t, "happy", mood.Questions[0].Buttons["5"])
+	}
+}
+
+func TestLoadDirectory(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "mood.yml", moodYAML)
+	writeFile(t, dir, "asleep.json", asleepJSON)
+	writeFile(t, dir, "notes.txt", "not a lifesheet")
+	sheet, err := lifesheet.Load(dir)
+	if !assert.NoError(t, err, "expected no error") {
+		t.FailNow()
+	}
+	assert.Len(t, sheet.Categories, 2)
+	assert.NoError(t, sheet.Validate())
+}
+
+func TestLoadInclude(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "categories"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(dir, "categories"), "mood.yaml", moodYAML)
+	main := writeFile(t, dir, "lifesheet.yaml", "include:\n  - categories\n  - asleep.json\n")
+	writeFile(t, dir, "asleep.json", asleepJSON)
+	sheet, err := lifesheet.Load(main)
+	if !assert.NoError(t, err, "expected no error") {
+		t.FailNow()
+	}
+	assert.Contains(t, sheet.Categories, "mood")
+	assert.Contains(t, sheet.Categories, "asleep")
+}
+
+func TestLoadDuplicates(t *testing.T) {
+	tests := []struct {
+		name   string
+		second string
+	}{
+		{name: "category", second: moodYAML},
+		{name: "question key", second: "other:\n  schedule: weekly\n  questions:\n    - key: mood\n      question: Again?\n      type: text\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFile(t, dir, "a.yaml", moodYAML)
+			writeFile(t, dir, "b.yaml", tt.second)
+			_, err := lifesheet.Load(dir)
+			assert.ErrorContains(t, err, "duplicate")
+		})
+	}
+}
+
+func TestQuestionFold(t *testing.T) {
+	sheet := &lifesheet.Lifesheet{Categories: map[string]lifesheet.Category{
+		"sleep": {Questions: []lifesheet.Question{{Key: "sleepHours", Type: "number"}}},
+	}}
+	q, ok := sheet.QuestionFold("sleephours")
+	assert.True(t, ok)
+	assert.Equal(t, "sleepHours", q.Key)
+	_, ok = sheet.Question("sleephours")
+	assert.False(t, ok)
+}
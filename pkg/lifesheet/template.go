@@ -0,0 +1,84 @@
+package lifesheet
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// TemplateData looks up past answers for question templates
+type TemplateData interface {
+	// Last returns the most recent answer for the key
+	Last(key string) (string, error)
+	// Streak returns the number of consecutive days the key was answered true
+	Streak(key string) (int, error)
+	// Average returns the average answer for the key over the last days
+	Average(key string, days int) (float64, error)
+}
+
+// templateFuncs are the functions available in question templates, e.g.
+// `Yesterday you slept {{last "sleep_hours"}}h` or `{{streak "workout"}} day streak!`
+func templateFuncs(data TemplateData) template.FuncMap {
+	return template.FuncMap{
+		"last": func(key string) (string, error) {
+			return data.Last(key)
+		},
+		"streak": func(key string) (int, error) {
+			return data.Streak(key)
+		},
+		"avg": func(key string, days int) (string, error) {
+			avg, err := data.Average(key, days)
+			if err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("%.1f", avg), nil
+		},
+	}
+}
+
+func isTemplate(text string) bool {
+	return strings.Contains(text, "{{")
+}
+
+func parseTemplate(text string, data TemplateData) (*template.Template, error) {
+	return template.New("question").Funcs(templateFuncs(data)).Parse(text)
+}
+
+// Render returns a copy of the question with the templates in its
+// text and replies executed against the data
+func (q Question) Render(data TemplateData) (Question, error) {
+	text, err := renderTemplate(q.Text, data)
+	if err != nil {
+		return q, fmt.Errorf("failed to render question %s. %w", q.Key, err)
+	}
+	rendered := q
+	rendered.Text = text
+	if len(q.Replies) == 0 {
+		return rendered, nil
+	}
+	rendered.Replies = map[string]string{}
+	for k, v := range q.Replies {
+		reply, err := renderTemplate(v, data)
+		if err != nil {
+			return q, fmt.Errorf("failed to render reply %s of question %s. %w", k, q.Key, err)
+		}
+		rendered.Replies[k] = reply
+	}
+	return rendered, nil
+}
+
+func renderTemplate(text string, data TemplateData) (string, error) {
+	if !isTemplate(text) {
+		return text, nil
+	}
+	tmpl, err := parseTemplate(text, data)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, nil); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,48 @@
+package lifesheet_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/imdevinc/mylife/pkg/lifesheet"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeData struct{}
+
+func (fakeData) Last(key string) (string, error) {
+	if key == "broken" {
+		return "", errors.New("database is down")
+	}
+	return "7.5", nil
+}
+
+func (fakeData) Streak(key string) (int, error) {
+	return 5, nil
+}
+
+func (fakeData) Average(key string, days int) (float64, error) {
+	return 3.25, nil
+}
+
+func TestRender(t *testing.T) {
+	q := lifesheet.Question{
+		Key:     "sleep_hours",
+		Text:    `Yesterday you slept {{last "sleep_hours"}}h, how about last night?`,
+		Replies: map[string]string{"true": `{{streak "workout"}} day streak! Mood is {{avg "mood" 7}} this week`},
+	}
+	rendered, err := q.Render(fakeData{})
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, "Yesterday you slept 7.5h, how about last night?", rendered.Text)
+	assert.Equal(t, "5 day streak! Mood is 3.2 this week", rendered.Replies["true"])
+	assert.Contains(t, q.Replies["true"], "{{", "expected the original question to be untouched")
+}
+
+func TestRenderError(t *testing.T) {
+	q := lifesheet.Question{Key: "sleep_hours", Text: `You slept {{last "broken"}}h`}
+	rendered, err := q.Render(fakeData{})
+	assert.Error(t, err)
+	assert.Equal(t, q.Text, rendered.Text)
+}
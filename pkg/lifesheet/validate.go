@@ -0,0 +1,217 @@
+package lifesheet
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+var validTypes = map[string]bool{
+	TypeHeader:      true,
+	TypeText:        true,
+	TypeRange:       true,
+	TypeBoolean:     true,
+	TypeLocation:    true,
+	TypeNumber:      true,
+	TypeTime:        true,
+	TypeDuration:    true,
+	TypeMultiselect: true,
+	TypePhoto:       true,
+}
+
+var validSchedules = map[string]bool{
+	ScheduleDaily:         true,
+	ScheduleWeekly:        true,
+	ScheduleFiveTimesADay: true,
+	ScheduleSpecific:      true,
+}
+
+// dailyCategories are the only categories the scheduler knows
+// when to ask for a daily schedule
+var dailyCategories = map[string]bool{
+	"awake":  true,
+	"asleep": true,
+}
+
+// builtinCommands are the bot's own commands, a category with one of
+// these names couldn't be asked through its command
+var builtinCommands = map[string]bool{
+	"track":     true,
+	"log":       true,
+	"graph":     true,
+	"search":    true,
+	"heatmap":   true,
+	"correlate": true,
+	"pause":     true,
+	"resume":    true,
+	"snooze":    true,
+	"skip":      true,
+	"skip_all":  true,
+	"streaks":   true,
+	"digest":    true,
+	"status":    true,
+	"timezone":  true,
+	"help":      true,
+}
+
+// IsBuiltinCommand reports if the category name is taken by a built-in command
+func IsBuiltinCommand(name string) bool {
+	return builtinCommands[strings.ToLower(name)]
+}
+
+var timeOfDay = regexp.MustCompile(`^([01]?[0-9]|2[0-3]):[0-5][0-9](:[0-5][0-9])?$`)
+
+// ValidationError describes a single problem in a lifesheet,
+// Path is the JSON path of the offending value
+type ValidationError struct {
+	Path    string
+	Message string
+}
+
+func (e ValidationError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Path, e.Message)
+}
+
+// ValidationErrors is every problem found in a lifesheet
+type ValidationErrors []ValidationError
+
+func (e ValidationErrors) Error() string {
+	lines := []string{}
+	for _, v := range e {
+		lines = append(lines, v.Error())
+	}
+	return fmt.Sprintf("invalid lifesheet.\n%s", strings.Join(lines, "\n"))
+}
+
+// Validate checks the lifesheet for problems that would otherwise only
+// show up at runtime. It returns ValidationErrors with every problem found,
+// or nil if the lifesheet is valid.
+func (l *Lifesheet) Validate() error {
+	errs := ValidationErrors{}
+	add := func(path string, format string, args ...interface{}) {
+		errs = append(errs, ValidationError{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+	names := []string{}
+	for name := range l.Categories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	if len(names) == 0 {
+		add("$", "no categories defined")
+	}
+	keys := map[string]string{}
+	for _, name := range names {
+		c := l.Categories[name]
+		path := fmt.Sprintf("$.%s", name)
+		if IsBuiltinCommand(name) {
+			add(path, "category name %q is a built-in command", name)
+		}
+		if !validSchedules[c.Schedule] {
+			add(path+".schedule", "invalid schedule %q", c.Schedule)
+		}
+		if c.Schedule == ScheduleDaily && !dailyCategories[name] {
+			add(path+".schedule", "daily schedule is only supported for the awake and asleep categories")
+		}
+		if c.Schedule == ScheduleSpecific && len(c.Times) == 0 {
+			add(path+".times", "specific schedule requires at least one time")
+		}
+		if c.Schedule != ScheduleSpecific && len(c.Times) > 0 {
+			add(path+".times", "times are only used with the specific schedule")
+		}
+		for i, t := range c.Times {
+			if !timeOfDay.MatchString(t) {
+				add(fmt.Sprintf("%s.times[%d]", path, i), "invalid time %q, expected HH:MM or HH:MM:SS", t)
+			}
+		}
+		if c.SkipIfAnsweredWithin != "" {
+			if d, err := time.ParseDuration(c.SkipIfAnsweredWithin); err != nil || d <= 0 {
+				add(path+".skipIfAnsweredWithin", "invalid duration %q", c.SkipIfAnsweredWithin)
+			}
+		}
+		if len(c.Questions) == 0 {
+			add(path+".questions", "no questions defined")
+		}
+		earlier := map[string]bool{}
+		for i, q := range c.Questions {
+			qPath := fmt.Sprintf("%s.questions[%d]", path, i)
+			if q.When != "" {
+				cond, err := ParseCondition(q.When)
+				if err != nil {
+					add(qPath+".when", "%v", err)
+				}
+				for _, k := range cond.Keys() {
+					if !earlier[k] {
+						add(qPath+".when", "condition refers to %q which isn't an earlier question in the category", k)
+					}
+				}
+			}
+			if q.Key != "" {
+				earlier[q.Key] = true
+			}
+			if q.Text == "" {
+				add(qPath+".question", "question text is empty")
+			}
+			if isTemplate(q.Text) {
+				if _, err := parseTemplate(q.Text, nil); err != nil {
+					add(qPath+".question", "invalid template. %v", err)
+				}
+			}
+			replies := []string{}
+			for k := range q.Replies {
+				replies = append(replies, k)
+			}
+			sort.Strings(replies)
+			for _, k := range replies {
+				if !isTemplate(q.Replies[k]) {
+					continue
+				}
+				if _, err := parseTemplate(q.Replies[k], nil); err != nil {
+					add(fmt.Sprintf("%s.replies.%s", qPath, k), "invalid template. %v", err)
+				}
+			}
+			if !validTypes[q.Type] {
+				add(qPath+".type", "invalid type %q", q.Type)
+			}
+			if q.Type == TypeHeader {
+				continue
+			}
+			if q.Key == "" {
+				add(qPath+".key", "key is required for %s questions", q.Type)
+				continue
+			}
+			if other, ok := keys[q.Key]; ok {
+				add(qPath+".key", "duplicate key %q, already used at %s", q.Key, other)
+			} else {
+				keys[q.Key] = qPath
+			}
+			if (q.Type == TypeRange || q.Type == TypeMultiselect) && len(q.Buttons) == 0 {
+				add(qPath+".buttons", "%s questions require buttons", q.Type)
+			}
+			if q.Type == TypeMultiselect {
+				for k := range q.Buttons {
+					if strings.Contains(k, ",") {
+						add(qPath+".buttons", "multiselect option %q can't contain a comma", k)
+					}
+				}
+			}
+			if q.Min != nil && q.Max != nil && *q.Min > *q.Max {
+				add(qPath+".min", "min %g is greater than max %g", *q.Min, *q.Max)
+			}
+			if q.Decimals != nil && (*q.Decimals < 0 || *q.Decimals > 10) {
+				add(qPath+".decimals", "decimals must be between 0 and 10")
+			}
+			if q.Type != TypeNumber && (q.Unit != "" || q.Min != nil || q.Max != nil || q.Decimals != nil || q.SameAsLast) {
+				add(qPath+".type", "unit, min, max, decimals and sameAsLast are only used with number questions")
+			}
+			for j, a := range q.Alerts {
+				a.validate(q, l.Categories, fmt.Sprintf("%s.alerts[%d]", qPath, j), add)
+			}
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
@@ -0,0 +1,164 @@
+package lifesheet_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/imdevinc/mylife/pkg/lifesheet"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateExampleSheet(t *testing.T) {
+	sheet, err := lifesheet.LoadFromFile("../../lifesheet.json")
+	if !assert.NoError(t, err, "expected no error") {
+		t.FailNow()
+	}
+	assert.NoError(t, sheet.Validate(), "expected example lifesheet to be valid")
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name  string
+		sheet lifesheet.Lifesheet
+		paths []string
+	}{
+		{
+			name: "valid",
+			sheet: lifesheet.Lifesheet{Categories: map[string]lifesheet.Category{
+				"mood": {
+					Schedule: "specific",
+					Times:    []string{"09:00", "21:30:00"},
+					Questions: []lifesheet.Question{
+						{Key: "mood", Text: "How are you?", Type: "range", Buttons: map[string]string{"1": "bad"}},
+						{Text: "Thanks", Type: "header"},
+					},
+				},
+			}},
+		},
+		{
+			name: "invalid type and missing key",
+			sheet: lifesheet.Lifesheet{Categories: map[string]lifesheet.Category{
+				"mood": {
+					Schedule: "weekly",
+					Questions: []lifesheet.Question{
+						{Key: "mood", Text: "How are you?", Type: "rnage"},
+						{Text: "Anything else?", Type: "text"},
+					},
+				},
+			}},
+			paths: []string{"$.mood.questions[0].type", "$.mood.questions[1].key"},
+		},
+		{
+			name: "duplicate keys across categories",
+			sheet: lifesheet.Lifesheet{Categories: map[string]lifesheet.Category{
+				"asleep": {
+					Schedule:  "daily",
+					Questions: []lifesheet.Question{{Key: "workout", Text: "Did you workout?", Type: "boolean"}},
+				},
+				"weekly": {
+					Schedule:  "weekly",
+					Questions: []lifesheet.Question{{Key: "workout", Text: "Did you workout?", Type: "boolean"}},
+				},
+			}},
+			paths: []string{"$.weekly.questions[0].key"},
+		},
+		{
+			name: "conditions on later or unknown questions",
+			sheet: lifesheet.Lifesheet{Categories: map[string]lifesheet.Category{
+				"asleep": {
+					Schedule: "daily",
+					Questions: []lifesheet.Question{
+						{Key: "workout_kind", Text: "What kind?", Type: "text", When: "workout == true"},
+						{Key: "workout", Text: "Did you workout?", Type: "boolean"},
+						{Key: "why", Text: "Why not?", Type: "text", When: "workout = false"},
+						{Key: "kind", Text: "What kind?", Type: "text", When: "workout == true"},
+					},
+				},
+			}},
+			paths: []string{"$.asleep.questions[0].when", "$.asleep.questions[2].when"},
+		},
+		{
+			name: "invalid schedule settings",
+			sheet: lifesheet.Lifesheet{Categories: map[string]lifesheet.Category{
+				"mood": {
+					Schedule:             "specific",
+					Times:                []string{"25:00", "noon"},
+					SkipIfAnsweredWithin: "a while",
+					Questions:            []lifesheet.Question{{Key: "mood", Text: "How are you?", Type: "text"}},
+				},
+				"lunch": {
+					Schedule:  "daily",
+					Questions: []lifesheet.Question{{Key: "lunch", Text: "What did you eat?", Type: "text"}},
+				},
+			}},
+			paths: []string{"$.lunch.schedule", "$.mood.times[0]", "$.mood.times[1]", "$.mood.skipIfAnsweredWithin"},
+		},
+		{
+			name: "category named like a command",
+			sheet: lifesheet.Lifesheet{Categories: map[string]lifesheet.Category{
+				"Status": {
+					Schedule:  "weekly",
+					Questions: []lifesheet.Question{{Key: "status", Text: "How is it going?", Type: "text"}},
+				},
+			}},
+			paths: []string{"$.Status"},
+		},
+		{
+			name: "valid alerts",
+			sheet: lifesheet.Lifesheet{Categories: map[string]lifesheet.Category{
+				"asleep": {
+					Schedule: "daily",
+					Questions: []lifesheet.Question{
+						{Key: "mood", Text: "How are you?", Type: "range", Buttons: map[string]string{"1": "bad"}, Alerts: []lifesheet.Alert{
+							{Below: float(2), Days: 3, Message: "Your mood has been low for 3 days, maybe reach out to someone?"},
+						}},
+						{Key: "workout", Text: "Did you workout?", Type: "boolean", Alerts: []lifesheet.Alert{
+							{Missing: true, Days: 7, Message: "No workout for a week, time to move!"},
+						}},
+					},
+				},
+			}},
+		},
+		{
+			name: "invalid alerts",
+			sheet: lifesheet.Lifesheet{Categories: map[string]lifesheet.Category{
+				"asleep": {
+					Schedule: "daily",
+					Questions: []lifesheet.Question{
+						{Key: "workout", Text: "Did you workout?", Type: "boolean", Alerts: []lifesheet.Alert{
+							{Missing: true, Days: 7, FollowUp: "asleep"},
+							{Below: float(1), Days: 3},
+							{Missing: true, Above: float(1), Days: 0, FollowUp: "support", Cooldown: "soon"},
+						}},
+					},
+				},
+			}},
+			paths: []string{
+				"$.asleep.questions[0].alerts[1]",
+				"$.asleep.questions[0].alerts[2]",
+				"$.asleep.questions[0].alerts[2]",
+				"$.asleep.questions[0].alerts[2].days",
+				"$.asleep.questions[0].alerts[2].followUp",
+				"$.asleep.questions[0].alerts[2].cooldown",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.sheet.Validate()
+			if len(tt.paths) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+			var problems lifesheet.ValidationErrors
+			if !assert.True(t, errors.As(err, &problems), "expected validation errors") {
+				t.FailNow()
+			}
+			paths := []string{}
+			for _, p := range problems {
+				paths = append(paths, p.Path)
+			}
+			assert.Equal(t, tt.paths, paths)
+		})
+	}
+}
@@ -0,0 +1,187 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/imdevinc/mylife/pkg/database"
+	"github.com/imdevinc/mylife/pkg/lifesheet"
+	"github.com/imdevinc/mylife/pkg/stats"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// alertSetting stores when each alert last fired, keyed by alertID
+const alertSetting string = "alerts"
+
+// firedAlert is an alert that passed its rule and cooldown
+type firedAlert struct {
+	message  string
+	followUp string
+}
+
+// CheckAlerts checks the alerts of the question with the given key,
+// it's called after an answer is saved
+func (s *Scheduler) CheckAlerts(key string) {
+	q, ok := s.Sheet.Question(key)
+	if !ok || len(q.Alerts) == 0 {
+		return
+	}
+	s.checkAlerts([]lifesheet.Question{q})
+}
+
+// checkAllAlerts checks the alerts of every question in the lifesheet
+func (s *Scheduler) checkAllAlerts() {
+	s.checkAlerts(s.alertQuestions())
+}
+
+// alertQuestions returns the questions that have alerts
+func (s *Scheduler) alertQuestions() []lifesheet.Question {
+	questions := []lifesheet.Question{}
+	for _, name := range s.categoryNames() {
+		for _, q := range s.Sheet.Categories[name].Questions {
+			if q.Key != "" && len(q.Alerts) > 0 {
+				questions = append(questions, q)
+			}
+		}
+	}
+	return questions
+}
+
+// checkAlerts sends the message of every alert that fires and isn't
+// cooling down, then asks their follow-up categories
+func (s *Scheduler) checkAlerts(questions []lifesheet.Question) {
+	fired, err := s.firedAlerts(context.TODO(), questions)
+	if err != nil {
+		log.WithError(err).Error("failed to check alerts")
+		return
+	}
+	for _, f := range fired {
+		s.Bot.SendMessage("⚠️ " + f.message)
+		if f.followUp != "" {
+			s.AskQuestions(f.followUp, s.Sheet.Categories[f.followUp].Questions)
+		}
+	}
+}
+
+func (s *Scheduler) firedAlerts(ctx context.Context, questions []lifesheet.Question) ([]firedAlert, error) {
+	// Checks after answers and the scheduled check could race each other
+	s.alertMu.Lock()
+	defer s.alertMu.Unlock()
+	if len(questions) == 0 {
+		return nil, nil
+	}
+	lastFired := map[string]int64{}
+	if err := s.Database.GetSetting(ctx, alertSetting, &lastFired); err != nil && !errors.Is(err, database.ErrNotFound) {
+		return nil, fmt.Errorf("failed to get alert cooldowns. %v", err)
+	}
+	now := s.clock.Now().In(s.Location())
+	keys := []string{}
+	days := 0
+	for _, q := range questions {
+		keys = append(keys, q.Key)
+		for _, a := range q.Alerts {
+			if a.Days > days {
+				days = a.Days
+			}
+		}
+	}
+	// One extra day in case today hasn't been answered yet
+	answers, err := s.Database.GetAnswersSince(ctx, keys, now.AddDate(0, 0, -days-1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get answers. %v", err)
+	}
+	byKey := map[string][]database.AnswerResponse{}
+	for _, a := range answers {
+		byKey[a.Key] = append(byKey[a.Key], a)
+	}
+
+	fired := []firedAlert{}
+	for _, q := range questions {
+		for i, a := range q.Alerts {
+			id := alertID(q.Key, i)
+			if last, ok := lastFired[id]; ok && now.Sub(time.Unix(last, 0)) < a.CooldownDuration() {
+				continue
+			}
+			if !alertFires(q, a, byKey[q.Key], now) {
+				continue
+			}
+			if a.Missing {
+				// Nothing is expected to be answered while check-ins are paused
+				if paused, err := s.isPaused(s.questionCategory(q.Key)); err != nil || paused {
+					continue
+				}
+			}
+			lastFired[id] = now.Unix()
+			fired = append(fired, firedAlert{message: a.Text(q.Key), followUp: a.FollowUp})
+		}
+	}
+	if len(fired) > 0 {
+		if err := s.Database.SaveSetting(ctx, alertSetting, lastFired); err != nil {
+			return nil, fmt.Errorf("failed to save alert cooldowns. %v", err)
+		}
+	}
+	return fired, nil
+}
+
+// alertID identifies an alert by its question and position
+func alertID(key string, index int) string {
+	return fmt.Sprintf("%s.%d", key, index)
+}
+
+// questionCategory returns the name of the category the question is in
+func (s *Scheduler) questionCategory(key string) string {
+	for name, c := range s.Sheet.Categories {
+		for _, q := range c.Questions {
+			if q.Key == key {
+				return name
+			}
+		}
+	}
+	return ""
+}
+
+// alertFires checks the alert's rule against the question's answers.
+// Threshold rules need a daily average past the threshold on each of the
+// last Days days, ending yesterday if today wasn't answered yet.
+func alertFires(q lifesheet.Question, a lifesheet.Alert, answers []database.AnswerResponse, now time.Time) bool {
+	if a.Missing {
+		since := now.Add(-time.Duration(a.Days) * 24 * time.Hour)
+		for _, answer := range answers {
+			if time.Unix(answer.Timestamp, 0).Before(since) {
+				continue
+			}
+			if q.Type != lifesheet.TypeBoolean || answer.Answer == "true" {
+				return false
+			}
+		}
+		return true
+	}
+	daily := map[string][]float64{}
+	for _, answer := range answers {
+		v, err := database.NumericValue(answer)
+		if err != nil {
+			continue
+		}
+		day := dayKey(answer.Year, time.Month(answer.Month), answer.Day)
+		daily[day] = append(daily[day], v)
+	}
+	day := now
+	if len(daily[dayKey(day.Date())]) == 0 {
+		day = day.AddDate(0, 0, -1)
+	}
+	for i := 0; i < a.Days; i++ {
+		values := daily[dayKey(day.Date())]
+		if len(values) == 0 {
+			return false
+		}
+		avg := stats.Mean(values)
+		if (a.Below != nil && avg >= *a.Below) || (a.Above != nil && avg <= *a.Above) {
+			return false
+		}
+		day = day.AddDate(0, 0, -1)
+	}
+	return true
+}
@@ -0,0 +1,135 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/imdevinc/mylife/pkg/chart"
+	"github.com/imdevinc/mylife/pkg/database"
+	"github.com/imdevinc/mylife/pkg/lifesheet"
+	"github.com/imdevinc/mylife/pkg/stats"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Correlate handles `/correlate <keyA> <keyB> [lag]`. It compares the
+// daily averages of both questions, with keyB lag days after keyA, and
+// sends the correlation along with a scatter plot.
+func (s *Scheduler) Correlate(args []string) {
+	if len(args) < 2 || len(args) > 3 {
+		s.Bot.SendMessage("Usage: /correlate <keyA> <keyB> [lag in days]")
+		return
+	}
+	questions := []lifesheet.Question{}
+	for _, key := range args[:2] {
+		q, ok := s.Sheet.QuestionFold(key)
+		if !ok || !correlatable(q) {
+			s.Bot.SendMessage(fmt.Sprintf("%s isn't a number, range, time, duration or yes/no question", key))
+			return
+		}
+		questions = append(questions, q)
+	}
+	lag := 0
+	if len(args) == 3 {
+		parsed, err := strconv.Atoi(strings.TrimSuffix(args[2], "d"))
+		if err != nil {
+			s.Bot.SendMessage(fmt.Sprintf("invalid lag %s, try 1 to compare with the next day", args[2]))
+			return
+		}
+		lag = parsed
+	}
+	answers, err := s.Database.GetAnswersSince(context.TODO(), []string{questions[0].Key, questions[1].Key}, time.Time{})
+	if err != nil {
+		log.WithError(err).Error("failed to get answers")
+		s.Bot.SendMessage(fmt.Sprintf("failed to get answers from database. %s", err))
+		return
+	}
+	a := stats.DailyMeans(samples(questions[0], answers))
+	b := stats.DailyMeans(samples(questions[1], answers))
+	xs, ys := stats.Split(stats.Align(a, b, lag))
+	pearson, err := stats.Pearson(xs, ys)
+	if errors.Is(err, stats.ErrNotEnoughData) {
+		s.Bot.SendMessage(fmt.Sprintf("Not enough days with both %s and %s answered (%d) to correlate them", questions[0].Key, questions[1].Key, len(xs)))
+		return
+	}
+	if err != nil {
+		s.Bot.SendMessage(err.Error())
+		return
+	}
+	spearman, _ := stats.Spearman(xs, ys)
+	lines := []string{
+		fmt.Sprintf("%s and %s%s", questions[0].Key, questions[1].Key, describeLag(lag)),
+		fmt.Sprintf("Pearson r = %.2f (%s)", pearson, stats.Strength(pearson)),
+		fmt.Sprintf("Spearman ρ = %.2f (%s)", spearman, stats.Strength(spearman)),
+		fmt.Sprintf("n = %d days", len(xs)),
+	}
+	s.Bot.SendMessage(strings.Join(lines, "\n"))
+	title := fmt.Sprintf("%s vs %s", questions[0].Key, questions[1].Key)
+	if err := s.Bot.SendImageURL(chart.ScatterURL(title, questions[0].Key, questions[1].Key, xs, ys)); err != nil {
+		log.WithError(err).Error("failed to send scatter plot")
+	}
+}
+
+// correlatable reports whether the question's answers are numbers
+func correlatable(q lifesheet.Question) bool {
+	switch q.Type {
+	case lifesheet.TypeNumber, lifesheet.TypeRange, lifesheet.TypeTime, lifesheet.TypeDuration, lifesheet.TypeBoolean:
+		return true
+	}
+	return false
+}
+
+// samples converts the question's answers into values on the day they were
+// given. Booleans become 1 or 0, times and durations are normalized
+// like they are on charts.
+func samples(q lifesheet.Question, answers []database.AnswerResponse) []stats.Sample {
+	days := []stats.Day{}
+	values := []float64{}
+	for _, a := range answers {
+		if a.Key != q.Key {
+			continue
+		}
+		var v float64
+		if q.Type == lifesheet.TypeBoolean {
+			if a.Answer != "true" && a.Answer != "false" {
+				continue
+			}
+			if a.Answer == "true" {
+				v = 1
+			}
+		} else {
+			parsed, err := database.NumericValue(a)
+			if err != nil {
+				continue
+			}
+			v = parsed
+		}
+		days = append(days, stats.Day{Year: a.Year, Month: time.Month(a.Month), Day: a.Day})
+		values = append(values, v)
+	}
+	values = chart.Normalize(q, values)
+	out := make([]stats.Sample, len(values))
+	for i := range values {
+		out[i] = stats.Sample{Day: days[i], Value: values[i]}
+	}
+	return out
+}
+
+func describeLag(lag int) string {
+	switch lag {
+	case 0:
+		return " on the same day"
+	case 1:
+		return " the next day"
+	case -1:
+		return " the day before"
+	}
+	if lag < 0 {
+		return fmt.Sprintf(" %d days before", -lag)
+	}
+	return fmt.Sprintf(" %d days later", lag)
+}
@@ -0,0 +1,296 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/imdevinc/mylife/pkg/chart"
+	"github.com/imdevinc/mylife/pkg/database"
+	"github.com/imdevinc/mylife/pkg/lifesheet"
+	"github.com/imdevinc/mylife/pkg/stats"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	digestDay  string = "day"
+	digestWeek string = "week"
+)
+
+// digestPeriod is the time range summarized by a digest. It's compared
+// against the period of the same length right before it.
+type digestPeriod struct {
+	name     string
+	start    time.Time
+	previous time.Time
+	end      time.Time
+}
+
+// newDigestPeriod returns today so far, or the last 7 days including today
+func newDigestPeriod(name string, now time.Time) digestPeriod {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	days := 1
+	if name == digestWeek {
+		days = 7
+	}
+	start := today.AddDate(0, 0, 1-days)
+	return digestPeriod{name: name, start: start, previous: start.AddDate(0, 0, -days), end: now}
+}
+
+// digest is the summary sent to the user, with an optional chart
+type digest struct {
+	lines    []string
+	chartURL string
+}
+
+// Digest handles the /digest command and the scheduled digests by sending
+// a summary of the answers given today or over the last week
+func (s *Scheduler) Digest(args []string) {
+	name := digestDay
+	if len(args) > 0 {
+		switch args[0] {
+		case digestDay, "daily", "today":
+			name = digestDay
+		case digestWeek, "weekly":
+			name = digestWeek
+		default:
+			s.Bot.SendMessage(fmt.Sprintf("unknown digest %s, try /digest day or /digest week", args[0]))
+			return
+		}
+	}
+	period := newDigestPeriod(name, s.clock.Now().In(s.Location()))
+	d, err := s.buildDigest(context.TODO(), period)
+	if err != nil {
+		log.WithError(err).Error("failed to build digest")
+		s.Bot.SendMessage(fmt.Sprintf("failed to build digest. %s", err))
+		return
+	}
+	s.Bot.SendMessage(strings.Join(d.lines, "\n"))
+	if d.chartURL != "" {
+		if err := s.Bot.SendImageURL(d.chartURL); err != nil {
+			log.WithError(err).Error("failed to send digest chart")
+		}
+	}
+}
+
+func (s *Scheduler) buildDigest(ctx context.Context, period digestPeriod) (digest, error) {
+	questions := []lifesheet.Question{}
+	keys := []string{}
+	for _, name := range s.categoryNames() {
+		for _, q := range s.Sheet.Categories[name].Questions {
+			if q.Key == "" || q.Type == lifesheet.TypeHeader {
+				continue
+			}
+			questions = append(questions, q)
+			keys = append(keys, q.Key)
+		}
+	}
+	if len(keys) == 0 {
+		return digest{lines: []string{"Nothing to summarize yet"}}, nil
+	}
+	answers, err := s.Database.GetAnswersSince(ctx, keys, period.previous)
+	if err != nil {
+		return digest{}, err
+	}
+	streaks := map[string]string{}
+	for _, q := range questions {
+		if q.Type != lifesheet.TypeBoolean {
+			continue
+		}
+		streak, err := s.streak(ctx, q.Key)
+		if err != nil {
+			return digest{}, err
+		}
+		if streak.Current > 1 {
+			streaks[q.Key] = formatStreak(streak.Current, s.streakUnit(q.Key))
+		}
+	}
+	return summarize(questions, answers, period, streaks), nil
+}
+
+// summarize builds the digest from the answers given since the start of
+// the previous period. Numeric answers are averaged and compared to the
+// previous period, booleans show how often they were done and text answers
+// (like gratitude entries) are listed. The numeric question answered most
+// often is charted.
+func summarize(questions []lifesheet.Question, answers []database.AnswerResponse, period digestPeriod, streaks map[string]string) digest {
+	current := map[string][]database.AnswerResponse{}
+	previous := map[string][]database.AnswerResponse{}
+	for _, a := range answers {
+		ts := time.Unix(a.Timestamp, 0)
+		switch {
+		case ts.Before(period.previous) || ts.After(period.end):
+		case ts.Before(period.start):
+			previous[a.Key] = append(previous[a.Key], a)
+		default:
+			current[a.Key] = append(current[a.Key], a)
+		}
+	}
+
+	title := "Today"
+	if period.name == digestWeek {
+		title = "This week"
+	}
+	d := digest{lines: []string{fmt.Sprintf("📋 %s (%s)", title, formatPeriod(period))}}
+	entries := []string{}
+	var charted lifesheet.Question
+	chartCount := 0
+	for _, q := range questions {
+		switch q.Type {
+		case lifesheet.TypeNumber, lifesheet.TypeRange, lifesheet.TypeTime, lifesheet.TypeDuration:
+			line, count := averageLine(q, current[q.Key], previous[q.Key])
+			if line == "" {
+				continue
+			}
+			d.lines = append(d.lines, line)
+			if count > chartCount {
+				charted = q
+				chartCount = count
+			}
+		case lifesheet.TypeBoolean:
+			if len(current[q.Key]) == 0 && streaks[q.Key] == "" {
+				continue
+			}
+			d.lines = append(d.lines, completionLine(q, current[q.Key], period, streaks[q.Key]))
+		case lifesheet.TypeText:
+			for _, a := range current[q.Key] {
+				if a.Answer != "" {
+					entries = append(entries, "  • "+a.Answer)
+				}
+			}
+		}
+	}
+	if len(entries) > 0 {
+		d.lines = append(d.lines, "", "🙏 Entries:")
+		d.lines = append(d.lines, entries...)
+	}
+	if len(d.lines) == 1 {
+		d.lines = append(d.lines, "No answers yet")
+	}
+	if chartCount > 1 {
+		d.chartURL = digestChart(charted, current[charted.Key], period)
+	}
+	return d
+}
+
+// averageLine compares the average of the numeric answers in both periods.
+// It returns an empty line if nothing was answered in the current period.
+func averageLine(q lifesheet.Question, current []database.AnswerResponse, previous []database.AnswerResponse) (string, int) {
+	cur := numericValues(current)
+	prev := numericValues(previous)
+	if len(cur) == 0 {
+		return "", 0
+	}
+	if q.Type == lifesheet.TypeTime {
+		// Normalize both periods together so they are cut at the same time
+		hours := chart.TimeOfDay(append(append([]float64{}, cur...), prev...))
+		for i := range hours {
+			hours[i] *= 60
+		}
+		cur, prev = hours[:len(cur)], hours[len(cur):]
+	}
+	avg := stats.Mean(cur)
+	line := fmt.Sprintf("%s: %s", q.Key, formatValue(q, avg))
+	if len(prev) > 0 {
+		line += fmt.Sprintf(" (%s vs %s)", formatChange(q, avg-stats.Mean(prev)), formatValue(q, stats.Mean(prev)))
+	}
+	return line, len(cur)
+}
+
+// completionLine shows if a boolean was done today, or on how many days
+// of the week it was done, along with the current streak
+func completionLine(q lifesheet.Question, answers []database.AnswerResponse, period digestPeriod, streak string) string {
+	days := map[string]bool{}
+	for _, a := range answers {
+		if a.Answer == "true" {
+			days[dayKey(a.Year, time.Month(a.Month), a.Day)] = true
+		}
+	}
+	var line string
+	if period.name == digestWeek {
+		line = fmt.Sprintf("%s: done on %d of 7 days", q.Key, len(days))
+	} else if len(days) > 0 {
+		line = fmt.Sprintf("%s: ✅", q.Key)
+	} else {
+		line = fmt.Sprintf("%s: ❌", q.Key)
+	}
+	if streak != "" {
+		line += ", " + streak
+	}
+	return line
+}
+
+// digestChart charts the answers of the current period
+func digestChart(q lifesheet.Question, answers []database.AnswerResponse, period digestPeriod) string {
+	layout := "15:04"
+	if period.name == digestWeek {
+		layout = "Mon"
+	}
+	values := numericValues(answers)
+	times := make([]string, len(values))
+	i := 0
+	for _, a := range answers {
+		if _, err := database.NumericValue(a); err != nil {
+			continue
+		}
+		times[i] = time.Unix(a.Timestamp, 0).In(period.end.Location()).Format(layout)
+		i++
+	}
+	return chart.LineURL(q.Key, chart.Normalize(q, values), times)
+}
+
+func numericValues(answers []database.AnswerResponse) []float64 {
+	values := []float64{}
+	for _, a := range answers {
+		v, err := database.NumericValue(a)
+		if err != nil {
+			continue
+		}
+		values = append(values, v)
+	}
+	return values
+}
+
+// formatValue formats an average the way the question is answered.
+// Times are expected as minutes, possibly past midnight.
+func formatValue(q lifesheet.Question, v float64) string {
+	switch q.Type {
+	case lifesheet.TypeTime:
+		return lifesheet.FormatTimeOfDay(int(math.Round(v)) % (24 * 60))
+	case lifesheet.TypeDuration:
+		return lifesheet.FormatDuration(time.Duration(math.Round(v)) * time.Second)
+	}
+	return strings.TrimSpace(lifesheet.FormatNumber(math.Round(v*10)/10) + " " + q.Unit)
+}
+
+// formatChange formats the difference between two averages with an arrow
+func formatChange(q lifesheet.Question, diff float64) string {
+	arrow := "▲"
+	if diff < 0 {
+		arrow = "▼"
+		diff = -diff
+	}
+	var change string
+	switch q.Type {
+	case lifesheet.TypeTime:
+		change = lifesheet.FormatDuration(time.Duration(math.Round(diff)) * time.Minute)
+	case lifesheet.TypeDuration:
+		change = lifesheet.FormatDuration(time.Duration(math.Round(diff)) * time.Second)
+	default:
+		change = lifesheet.FormatNumber(math.Round(diff*10) / 10)
+	}
+	if change == "0" || change == "0s" {
+		return "="
+	}
+	return arrow + " " + change
+}
+
+func formatPeriod(period digestPeriod) string {
+	if period.name == digestWeek {
+		return period.start.Format("Jan 2") + " - " + period.end.Format("Jan 2")
+	}
+	return period.start.Format("Mon Jan 2")
+}
@@ -0,0 +1,91 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/imdevinc/mylife/pkg/chart"
+	"github.com/imdevinc/mylife/pkg/lifesheet"
+	"github.com/imdevinc/mylife/pkg/stats"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Heatmap handles `/heatmap <key> [year]` by sending a calendar of the
+// year colored by the daily average, or how often a boolean was true
+func (s *Scheduler) Heatmap(args []string) {
+	if len(args) < 1 || len(args) > 2 {
+		s.Bot.SendMessage("Usage: /heatmap <key> [year]")
+		return
+	}
+	q, ok := s.Sheet.QuestionFold(args[0])
+	if !ok || !correlatable(q) {
+		s.Bot.SendMessage(fmt.Sprintf("%s isn't a number, range, time, duration or yes/no question", args[0]))
+		return
+	}
+	loc := s.Location()
+	year := s.clock.Now().In(loc).Year()
+	if len(args) == 2 {
+		parsed, err := strconv.Atoi(args[1])
+		if err != nil || parsed < 1 {
+			s.Bot.SendMessage(fmt.Sprintf("invalid year %s", args[1]))
+			return
+		}
+		year = parsed
+	}
+	// Answers given in other timezones may be a day off, fetch a little more
+	since := time.Date(year, time.January, 1, 0, 0, 0, 0, loc).AddDate(0, 0, -1)
+	answers, err := s.Database.GetAnswersSince(context.TODO(), []string{q.Key}, since)
+	if err != nil {
+		log.WithError(err).Error("failed to get answers")
+		s.Bot.SendMessage(fmt.Sprintf("failed to get answers from database. %s", err))
+		return
+	}
+	days := map[stats.Day]float64{}
+	for d, v := range stats.DailyMeans(samples(q, answers)) {
+		if d.Year == year {
+			days[d] = v
+		}
+	}
+	if len(days) == 0 {
+		s.Bot.SendMessage(fmt.Sprintf("No answers for %s in %d", q.Key, year))
+		return
+	}
+	min, max, legend := heatmapScale(q, days)
+	data, err := chart.HeatmapPNG(year, days, min, max)
+	if err != nil {
+		log.WithError(err).Error("failed to render heatmap")
+		s.Bot.SendMessage(err.Error())
+		return
+	}
+	caption := fmt.Sprintf("%s in %d, %d days answered. %s", q.Key, year, len(days), legend)
+	if err := s.Bot.SendImage(fmt.Sprintf("%s-%d.png", q.Key, year), data, caption); err != nil {
+		log.WithError(err).Error("failed to send heatmap")
+	}
+}
+
+// heatmapScale returns the range of values the colors go through and a
+// legend describing it. Booleans always go from never to always done.
+func heatmapScale(q lifesheet.Question, days map[stats.Day]float64) (float64, float64, string) {
+	if q.Type == lifesheet.TypeBoolean {
+		return 0, 1, "Dark green is done"
+	}
+	values := []float64{}
+	for _, v := range days {
+		values = append(values, v)
+	}
+	min, max := stats.Bounds(values)
+	return min, max, fmt.Sprintf("Light green is %s, dark green is %s", formatChartValue(q, min), formatChartValue(q, max))
+}
+
+// formatChartValue formats a value normalized for charts, where times
+// are hours and durations are minutes
+func formatChartValue(q lifesheet.Question, v float64) string {
+	if q.Type == lifesheet.TypeTime || q.Type == lifesheet.TypeDuration {
+		// Back to the minutes and seconds they are stored as
+		return formatValue(q, v*60)
+	}
+	return formatValue(q, v)
+}
@@ -0,0 +1,107 @@
+package scheduler
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/imdevinc/mylife/pkg/bot"
+	"github.com/imdevinc/mylife/pkg/lifesheet"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// builtinCommands are the commands that don't come from the lifesheet
+var builtinCommands = []bot.Command{
+	{Name: "track", Description: "Answer a single question: /track <key>"},
+	{Name: "log", Description: "Log an answer right away: /log <key> <value> [note]"},
+	{Name: "graph", Description: "Graph past answers: /graph <key>[,<key>] [90d] [ma7] [weekly] [trend], 30 days by default"},
+	{Name: "search", Description: "Search your written answers: /search <terms>"},
+	{Name: "heatmap", Description: "Show a calendar of daily answers: /heatmap <key> [year]"},
+	{Name: "correlate", Description: "Compare two questions: /correlate <keyA> <keyB> [lag]"},
+	{Name: "pause", Description: "Pause check-ins: /pause [3d|until YYYY-MM-DD] [categories]"},
+	{Name: "resume", Description: "Resume paused check-ins: /resume [categories]"},
+	{Name: "snooze", Description: "Ask the current check-in later: /snooze [15m|1h]"},
+	{Name: "skip", Description: "Skip the current question"},
+	{Name: "skip_all", Description: "Skip the rest of the check-in"},
+	{Name: "streaks", Description: "Show your yes/no habit streaks"},
+	{Name: "digest", Description: "Summarize your answers: /digest [day|week]"},
+	{Name: "status", Description: "Show the current check-in, pauses and schedule"},
+	{Name: "timezone", Description: "Show or change the timezone: /timezone [Europe/Berlin]"},
+	{Name: "help", Description: "List categories and questions"},
+}
+
+// commandName is what Telegram accepts as a command
+var commandName = regexp.MustCompile(`^[a-z0-9_]{1,32}$`)
+
+// Commands returns a command for every lifesheet category,
+// followed by the built-in commands
+func (s *Scheduler) Commands() []bot.Command {
+	commands := []bot.Command{}
+	for _, name := range s.categoryNames() {
+		command := strings.ToLower(name)
+		if !commandName.MatchString(command) {
+			log.WithField("category", name).Warn("category can't be registered as a command")
+			continue
+		}
+		if lifesheet.IsBuiltinCommand(command) {
+			log.WithField("category", name).Warn("category is named like a built-in command")
+			continue
+		}
+		description := s.Sheet.Categories[name].Description
+		if description == "" {
+			description = fmt.Sprintf("Ask the %s questions", name)
+		}
+		commands = append(commands, bot.Command{Name: command, Description: description})
+	}
+	return append(commands, builtinCommands...)
+}
+
+// Help handles the /help command by listing every category with its
+// schedule and questions, followed by the built-in commands
+func (s *Scheduler) Help() {
+	lines := []string{}
+	for _, name := range s.categoryNames() {
+		c := s.Sheet.Categories[name]
+		lines = append(lines, fmt.Sprintf("/%s - %s (%s)", strings.ToLower(name), c.Description, describeSchedule(name, c)))
+		for _, q := range c.Questions {
+			if q.Type == lifesheet.TypeHeader {
+				continue
+			}
+			lines = append(lines, fmt.Sprintf("  • %s (%s): %s", q.Key, q.Type, q.Text))
+		}
+	}
+	lines = append(lines, "")
+	for _, c := range builtinCommands {
+		lines = append(lines, fmt.Sprintf("/%s - %s", c.Name, c.Description))
+	}
+	s.Bot.SendMessage(strings.Join(lines, "\n"))
+}
+
+// describeSchedule explains when a category is asked, matching scheduleJobs
+func describeSchedule(name string, c lifesheet.Category) string {
+	switch c.Schedule {
+	case lifesheet.ScheduleDaily:
+		if name == "awake" {
+			return "daily at 08:00"
+		}
+		return "daily at 22:00"
+	case lifesheet.ScheduleWeekly:
+		return "Mondays at 08:00"
+	case lifesheet.ScheduleFiveTimesADay:
+		return "daily at 09:00, 12:00, 15:00, 18:00 and 21:00"
+	case lifesheet.ScheduleSpecific:
+		return fmt.Sprintf("daily at %s", strings.Join(c.Times, ", "))
+	}
+	return c.Schedule
+}
+
+func (s *Scheduler) categoryNames() []string {
+	names := []string{}
+	for name := range s.Sheet.Categories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,198 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/imdevinc/mylife/pkg/database"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const pausesSetting string = "pauses"
+
+var dayDuration = regexp.MustCompile(`^(\d+)([dw])$`)
+
+// ParseDuration extends time.ParseDuration with day (d) and week (w) units
+func ParseDuration(raw string) (time.Duration, error) {
+	if m := dayDuration.FindStringSubmatch(raw); m != nil {
+		n, err := strconv.Atoi(m[1])
+		if err != nil {
+			return 0, err
+		}
+		day := 24 * time.Hour
+		if m[2] == "w" {
+			return time.Duration(n) * 7 * day, nil
+		}
+		return time.Duration(n) * day, nil
+	}
+	return time.ParseDuration(raw)
+}
+
+// getPauses loads every pause that was ever saved, including expired
+// ones which are kept around as history
+func (s *Scheduler) getPauses(ctx context.Context) ([]database.Pause, error) {
+	pauses := []database.Pause{}
+	err := s.Database.GetSetting(ctx, pausesSetting, &pauses)
+	if err != nil && !errors.Is(err, database.ErrNotFound) {
+		return nil, err
+	}
+	return pauses, nil
+}
+
+// activePauses returns the pauses that are in effect at the given time
+func activePauses(pauses []database.Pause, now time.Time) []database.Pause {
+	active := []database.Pause{}
+	for _, p := range pauses {
+		if p.From > now.Unix() {
+			continue
+		}
+		if p.Until != 0 && p.Until <= now.Unix() {
+			continue
+		}
+		active = append(active, p)
+	}
+	return active
+}
+
+// isPaused checks if scheduled check-ins for the category are suspended
+func (s *Scheduler) isPaused(category string) (bool, error) {
+	pauses, err := s.getPauses(context.TODO())
+	if err != nil {
+		return false, err
+	}
+	for _, p := range activePauses(pauses, s.clock.Now()) {
+		if p.Category == "" || p.Category == category {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Pause handles the /pause command. Arguments are an optional duration
+// (e.g. "3d") or "until YYYY-MM-DD", which includes that whole day,
+// followed by the categories to pause.
+// If no categories are given then every category is paused.
+func (s *Scheduler) Pause(args []string) {
+	now := s.clock.Now().In(s.Location())
+	var until int64
+	if len(args) > 0 && args[0] == "until" {
+		if len(args) < 2 {
+			s.Bot.SendMessage("Please provide a date, e.g. /pause until 2006-01-02")
+			return
+		}
+		date, err := time.ParseInLocation("2006-01-02", args[1], now.Location())
+		if err != nil {
+			s.Bot.SendMessage(fmt.Sprintf("invalid date %s, expected YYYY-MM-DD", args[1]))
+			return
+		}
+		until = date.AddDate(0, 0, 1).Unix()
+		args = args[2:]
+	} else if len(args) > 0 {
+		if d, err := ParseDuration(args[0]); err == nil {
+			until = now.Add(d).Unix()
+			args = args[1:]
+		}
+	}
+	if until != 0 && until <= now.Unix() {
+		s.Bot.SendMessage("The pause has to end in the future")
+		return
+	}
+	for _, c := range args {
+		if _, ok := s.Sheet.Categories[c]; !ok {
+			s.Bot.SendMessage(fmt.Sprintf("unknown category %s", c))
+			return
+		}
+	}
+	categories := args
+	if len(categories) == 0 {
+		categories = []string{""}
+	}
+	pauses, err := s.getPauses(context.TODO())
+	if err != nil {
+		log.WithError(err).Error("failed to get pauses")
+		s.Bot.SendMessage(fmt.Sprintf("failed to get pauses from database. %s", err))
+		return
+	}
+	for _, c := range categories {
+		pauses = append(pauses, database.Pause{Category: c, From: now.Unix(), Until: until})
+	}
+	if err := s.Database.SaveSetting(context.TODO(), pausesSetting, pauses); err != nil {
+		log.WithError(err).Error("failed to save pauses")
+		s.Bot.SendMessage(fmt.Sprintf("failed to save pause to database. %s", err))
+		return
+	}
+	s.Bot.SendMessage(pauseStatus(pauses, now))
+}
+
+// Resume handles the /resume command by ending the active pauses for the
+// given categories, or every active pause if no categories are given
+func (s *Scheduler) Resume(args []string) {
+	now := s.clock.Now().In(s.Location())
+	pauses, err := s.getPauses(context.TODO())
+	if err != nil {
+		log.WithError(err).Error("failed to get pauses")
+		s.Bot.SendMessage(fmt.Sprintf("failed to get pauses from database. %s", err))
+		return
+	}
+	resumed := 0
+	for i, p := range pauses {
+		if len(activePauses([]database.Pause{p}, now)) == 0 {
+			continue
+		}
+		if len(args) > 0 && !contains(args, p.Category) {
+			continue
+		}
+		// Keep the pause as history so streaks can take it into account
+		pauses[i].Until = now.Unix()
+		resumed++
+	}
+	if resumed == 0 {
+		s.Bot.SendMessage("Nothing to resume")
+		return
+	}
+	if err := s.Database.SaveSetting(context.TODO(), pausesSetting, pauses); err != nil {
+		log.WithError(err).Error("failed to save pauses")
+		s.Bot.SendMessage(fmt.Sprintf("failed to save pauses to database. %s", err))
+		return
+	}
+	s.Bot.SendMessage(pauseStatus(pauses, now))
+}
+
+// pauseStatus describes the active pauses and how long they have left,
+// formatting times in the location of now
+func pauseStatus(pauses []database.Pause, now time.Time) string {
+	active := activePauses(pauses, now)
+	if len(active) == 0 {
+		return "Check-ins are not paused"
+	}
+	lines := []string{}
+	for _, p := range active {
+		name := p.Category
+		if name == "" {
+			name = "All check-ins"
+		}
+		if p.Until == 0 {
+			lines = append(lines, fmt.Sprintf("%s paused until resumed", name))
+			continue
+		}
+		until := time.Unix(p.Until, 0).In(now.Location())
+		remaining := until.Sub(now).Round(time.Minute)
+		lines = append(lines, fmt.Sprintf("%s paused until %s (%s remaining)", name, until.Format("Mon Jan 2 15:04"), remaining))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,123 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/imdevinc/mylife/pkg/database"
+	"github.com/imdevinc/mylife/pkg/lifesheet"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// QuickLog handles `/log <key> <value> [note]`, saving an answer without
+// asking the question first. The value is validated like a regular answer.
+func (s *Scheduler) QuickLog(args []string) {
+	if len(args) < 2 {
+		s.Bot.SendMessage("Usage: /log <key> <value> [note]")
+		return
+	}
+	q, ok := s.Sheet.QuestionFold(args[0])
+	if !ok {
+		s.Bot.SendMessage(fmt.Sprintf("unknown question key %s", args[0]))
+		return
+	}
+	s.quickLog(q, args[1:])
+}
+
+// QuickLogShorthand handles a `key value [note]` message sent while no
+// question is pending
+func (s *Scheduler) QuickLogShorthand(text string) {
+	args := strings.Fields(text)
+	q, ok := s.Sheet.QuestionFold(firstField(args))
+	if !ok || len(args) < 2 {
+		s.Bot.SendMessage("I didn't ask a question")
+		return
+	}
+	s.quickLog(q, args[1:])
+}
+
+func (s *Scheduler) quickLog(q lifesheet.Question, args []string) {
+	answer, note, err := parseQuickLog(q, args)
+	if err != nil {
+		s.Bot.SendMessage(err.Error())
+		return
+	}
+	if err := s.Database.SaveAnswer(context.TODO(), database.AnswerResponse{
+		Question: q.Text,
+		Key:      q.Key,
+		Answer:   answer.Text,
+		Value:    answer.Value,
+		Values:   answer.Values,
+		Note:     note,
+		Source:   "quicklog",
+		Type:     q.Type,
+		Timezone: s.Location().String(),
+	}); err != nil {
+		log.WithError(err).Error("failed to save quick log")
+		s.Bot.SendMessage(fmt.Sprintf("failed to save answer to database. %s", err))
+		return
+	}
+	s.Bot.SendMessage(fmt.Sprintf("Logged %s = %s 👍", q.Key, answer.Text))
+	if q.Type == lifesheet.TypeBoolean && answer.Text == "true" {
+		s.ReplyStreak(q.Key)
+	}
+	s.CheckAlerts(q.Key)
+}
+
+// parseQuickLog splits the arguments into the answer and an optional note.
+// Text answers use every argument, times and durations may be written as
+// two words (e.g. "11:40 pm" or "1h 30m"). Without buttons to tap, yes/no
+// and range answers are checked here since anything could be typed.
+func parseQuickLog(q lifesheet.Question, args []string) (lifesheet.ParsedAnswer, string, error) {
+	switch q.Type {
+	case lifesheet.TypeHeader, lifesheet.TypeLocation, lifesheet.TypePhoto:
+		return lifesheet.ParsedAnswer{}, "", fmt.Errorf("%s questions can't be quick logged", q.Type)
+	case lifesheet.TypeText:
+		answer, err := q.ParseAnswer(strings.Join(args, " "))
+		return answer, "", err
+	case lifesheet.TypeBoolean:
+		answer, err := parseQuickBoolean(args[0])
+		return answer, strings.Join(args[1:], " "), err
+	case lifesheet.TypeRange:
+		answer, err := parseQuickRange(q, args[0])
+		return answer, strings.Join(args[1:], " "), err
+	case lifesheet.TypeTime, lifesheet.TypeDuration:
+		if len(args) > 1 {
+			if answer, err := q.ParseAnswer(strings.Join(args[:2], " ")); err == nil {
+				return answer, strings.Join(args[2:], " "), nil
+			}
+		}
+	}
+	answer, err := q.ParseAnswer(args[0])
+	return answer, strings.Join(args[1:], " "), err
+}
+
+// parseQuickBoolean accepts yes/no answers and stores them like the buttons
+func parseQuickBoolean(text string) (lifesheet.ParsedAnswer, error) {
+	switch strings.ToLower(text) {
+	case "true", "yes", "y":
+		return lifesheet.ParsedAnswer{Text: "true"}, nil
+	case "false", "no", "n":
+		return lifesheet.ParsedAnswer{Text: "false"}, nil
+	}
+	return lifesheet.ParsedAnswer{}, fmt.Errorf("please answer with yes or no")
+}
+
+// parseQuickRange accepts a button key or label and stores the key
+func parseQuickRange(q lifesheet.Question, text string) (lifesheet.ParsedAnswer, error) {
+	for k, label := range q.Buttons {
+		if strings.EqualFold(text, k) || strings.EqualFold(text, label) {
+			return lifesheet.ParsedAnswer{Text: k}, nil
+		}
+	}
+	return lifesheet.ParsedAnswer{}, fmt.Errorf("please pick one of the options")
+}
+
+func firstField(fields []string) string {
+	if len(fields) == 0 {
+		return ""
+	}
+	return fields[0]
+}
@@ -1,6 +1,8 @@
 package scheduler
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"sync"
@@ -8,6 +10,8 @@ import (
 
 	"github.com/go-co-op/gocron"
 	"github.com/imdevinc/mylife/pkg/bot"
+	"github.com/imdevinc/mylife/pkg/clock"
+	"github.com/imdevinc/mylife/pkg/database"
 	"github.com/imdevinc/mylife/pkg/lifesheet"
 
 	log "github.com/sirupsen/logrus"
@@ -16,63 +20,196 @@ import (
 // SchedulerConfig holds the construction information
 // for a new Scheduler
 type SchedulerConfig struct {
-	Bot   *bot.Telegram
-	Sheet *lifesheet.Lifesheet
+	Bot      *bot.Telegram
+	Sheet    *lifesheet.Lifesheet
+	Database database.Database
+	// Location is the home timezone, it can be changed with /timezone
+	Location *time.Location
+	// Clock defaults to the system time
+	Clock clock.Clock
+	// DigestTime is when the daily digest is sent, and the weekly one on
+	// Sundays. No digests are scheduled if it's empty.
+	DigestTime string
+	// AlertTime is when every alert is checked, so alerts about missing
+	// answers fire even if nothing is answered. Alerts are only checked
+	// after answers if it's empty.
+	AlertTime string
 }
 
 // Scheduler handles the calls to Scheduler
 type Scheduler struct {
-	Bot *bot.Telegram
+	Bot      *bot.Telegram
+	Sheet    *lifesheet.Lifesheet
+	Database database.Database
+
+	clock      clock.Clock
+	digestTime string
+	alertTime  string
+	// sessionMu makes sure only one check-in is asked at a time
+	sessionMu sync.Mutex
+	// alertMu guards the alert cooldowns saved in the database
+	alertMu sync.Mutex
+
+	mu       sync.Mutex
+	cron     *gocron.Scheduler
+	location *time.Location
+	active   *session
+	queued   []*session
+	snoozes  []*snoozedSession
+}
+
+// session tracks the progress of a running AskQuestions call
+type session struct {
+	category  string
+	questions []lifesheet.Question
+	index     int
+	asked     time.Time
+	snooze    time.Duration
+	// answers holds the answers given so far, used for `when` conditions
+	answers map[string]string
+}
+
+// pollInterval is how often AskQuestions checks for a response
+const pollInterval = 100 * time.Millisecond
+
+// questionTimeout is how long the user has to answer a question
+// before the rest of the check-in is skipped
+const questionTimeout = 30 * time.Minute
+
+// New creates a Scheduler that is shared between the scheduled
+// check-ins and the commands sent to the bot
+func New(cfg *SchedulerConfig) *Scheduler {
+	loc := cfg.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	clk := cfg.Clock
+	if clk == nil {
+		clk = clock.Real{}
+	}
+	return &Scheduler{Bot: cfg.Bot, Sheet: cfg.Sheet, Database: cfg.Database, location: loc, clock: clk, digestTime: cfg.DigestTime, alertTime: cfg.AlertTime}
 }
 
-// Start initiates a new scheduler and schedules questions
-// to be asked at a specific time
-func Start(cfg *SchedulerConfig) error {
-	s := Scheduler{Bot: cfg.Bot}
-	sched := gocron.NewScheduler(time.Local)
-	for k, c := range cfg.Sheet.Categories {
+// Start schedules questions to be asked at a specific time
+// and blocks while the scheduler is running
+func (s *Scheduler) Start() error {
+	if err := s.loadSavedTimezone(context.TODO()); err != nil {
+		log.WithError(err).Error("failed to load saved timezone")
+	}
+	s.mu.Lock()
+	s.cron = gocron.NewScheduler(s.location)
+	err := s.scheduleJobs()
+	s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	log.Info("scheduler started")
+	s.cron.StartBlocking()
+	return nil
+}
+
+// scheduleJobs creates a job for every scheduled check-in
+// in the lifesheet. The caller must hold s.mu.
+func (s *Scheduler) scheduleJobs() error {
+	sched := s.cron
+	for k, c := range s.Sheet.Categories {
 		switch c.Schedule {
 		case "daily":
 			if k == "awake" {
-				sched.Every(1).Day().At("08:00:00").Do(s.AskQuestions, c.Questions)
+				sched.Every(1).Day().At("08:00:00").Tag(k).Do(s.askScheduled, k, c)
 			} else if k == "asleep" {
-				sched.Every(1).Day().At("22:00:00").Do(s.AskQuestions, c.Questions)
+				sched.Every(1).Day().At("22:00:00").Tag(k).Do(s.askScheduled, k, c)
 			}
 		case "weekly":
-			sched.Every(1).Monday().At("08:00:00").Do(s.AskQuestions, c.Questions)
+			sched.Every(1).Monday().At("08:00:00").Tag(k).Do(s.askScheduled, k, c)
 		case "fiveTimesADay":
-			sched.Every(1).Day().At("09:00").Do(s.AskQuestions, c.Questions)
-			sched.Every(1).Day().At("12:00").Do(s.AskQuestions, c.Questions)
-			sched.Every(1).Day().At("15:00").Do(s.AskQuestions, c.Questions)
-			sched.Every(1).Day().At("18:00").Do(s.AskQuestions, c.Questions)
-			sched.Every(1).Day().At("21:00").Do(s.AskQuestions, c.Questions)
+			sched.Every(1).Day().At("09:00").Tag(k).Do(s.askScheduled, k, c)
+			sched.Every(1).Day().At("12:00").Tag(k).Do(s.askScheduled, k, c)
+			sched.Every(1).Day().At("15:00").Tag(k).Do(s.askScheduled, k, c)
+			sched.Every(1).Day().At("18:00").Tag(k).Do(s.askScheduled, k, c)
+			sched.Every(1).Day().At("21:00").Tag(k).Do(s.askScheduled, k, c)
 		case "specific":
 			for _, t := range c.Times {
-				sched.Every(1).Day().At(t).Do(s.AskQuestions, c.Questions)
+				sched.Every(1).Day().At(t).Tag(k).Do(s.askScheduled, k, c)
 			}
 		default:
 			return fmt.Errorf("invalid schedule. %s", c.Schedule)
 		}
 	}
-	log.Info("scheduler started")
-	sched.StartBlocking()
+	if s.digestTime != "" {
+		if _, err := sched.Every(1).Day().At(s.digestTime).Tag("digest").Do(s.Digest, []string{digestDay}); err != nil {
+			return fmt.Errorf("invalid digest time. %v", err)
+		}
+		if _, err := sched.Every(1).Sunday().At(s.digestTime).Tag("weekly digest").Do(s.Digest, []string{digestWeek}); err != nil {
+			return fmt.Errorf("invalid digest time. %v", err)
+		}
+	}
+	if s.alertTime != "" && len(s.alertQuestions()) > 0 {
+		if _, err := sched.Every(1).Day().At(s.alertTime).Tag("alerts").Do(s.checkAllAlerts); err != nil {
+			return fmt.Errorf("invalid alert time. %v", err)
+		}
+	}
 	return nil
 }
 
 // ProcessCommand looks at the key being provided to determine
 // which set of questions to ask
-func ProcessCommand(cfg *SchedulerConfig, key string) {
-	s := Scheduler{Bot: cfg.Bot}
-	key = strings.ToLower(key)
+func (s *Scheduler) ProcessCommand(text string) {
+	key := strings.ToLower(text)
+	fields := strings.Fields(key)
+	if len(fields) > 0 {
+		switch fields[0] {
+		case "timezone":
+			// Timezone names are case sensitive
+			s.SetTimezone(strings.Fields(text)[1:])
+			return
+		case "pause":
+			s.Pause(fields[1:])
+			return
+		case "resume":
+			s.Resume(fields[1:])
+			return
+		case "snooze":
+			s.Snooze(fields[1:])
+			return
+		case "status":
+			s.Status()
+			return
+		case "help":
+			s.Help()
+			return
+		case "search":
+			s.Search(fields[1:])
+			return
+		case "heatmap":
+			s.Heatmap(fields[1:])
+			return
+		case "correlate":
+			s.Correlate(fields[1:])
+			return
+		case "streaks":
+			s.Streaks()
+			return
+		case "digest":
+			s.Digest(fields[1:])
+			return
+		case "log":
+			// Keep the case of notes and text answers
+			s.QuickLog(strings.Fields(text)[1:])
+			return
+		}
+	}
 	var questionKey string
 	if strings.HasPrefix(key, "track ") {
 		questionKey = strings.TrimPrefix(key, "track ")
 	}
-	for k, c := range cfg.Sheet.Categories {
+	for k, c := range s.Sheet.Categories {
 		if questionKey != "" {
 			for _, q := range c.Questions {
 				if strings.ToLower(q.Key) == questionKey {
-					s.AskQuestions([]lifesheet.Question{q})
+					// Tracking a single question always asks it
+					q.When = ""
+					s.AskQuestions(k, []lifesheet.Question{q})
 					return
 				}
 			}
@@ -81,20 +218,103 @@ func ProcessCommand(cfg *SchedulerConfig, key string) {
 				continue
 			}
 			if questionKey == "" {
-				s.AskQuestions(c.Questions)
+				s.AskQuestions(k, c.Questions)
 				return
 			}
 		}
 	}
 }
 
+// askScheduled is used by scheduled check-ins to only ask the questions
+// that haven't been answered within the category's skipIfAnsweredWithin
+// window, and to honor any active pauses. Manually requested check-ins
+// always ask every question.
+func (s *Scheduler) askScheduled(name string, c lifesheet.Category) {
+	paused, err := s.isPaused(name)
+	if err != nil {
+		log.WithError(err).Error("failed to check if check-in is paused")
+	}
+	if paused {
+		log.WithField("category", name).Info("check-in is paused, skipping")
+		return
+	}
+	questions, err := s.unansweredQuestions(c)
+	if err != nil {
+		log.WithError(err).Error("failed to check for recent answers")
+		questions = c.Questions
+	}
+	if len(questions) == 0 {
+		log.Info("all questions were recently answered, skipping check-in")
+		return
+	}
+	s.AskQuestions(name, questions)
+}
+
+// unansweredQuestions returns the questions in the category that don't have
+// an answer within the skipIfAnsweredWithin window. Headers are only kept
+// if there is at least one question left to ask.
+func (s *Scheduler) unansweredQuestions(c lifesheet.Category) ([]lifesheet.Question, error) {
+	if c.SkipIfAnsweredWithin == "" {
+		return c.Questions, nil
+	}
+	window, err := time.ParseDuration(c.SkipIfAnsweredWithin)
+	if err != nil {
+		return nil, fmt.Errorf("invalid skipIfAnsweredWithin. %v", err)
+	}
+	keys := []string{}
+	for _, q := range c.Questions {
+		if q.Key != "" {
+			keys = append(keys, q.Key)
+		}
+	}
+	if len(keys) == 0 {
+		return c.Questions, nil
+	}
+	answers, err := s.Database.GetAnswersSince(context.TODO(), keys, s.clock.Now().Add(-window))
+	if err != nil {
+		return nil, err
+	}
+	answered := map[string]bool{}
+	for _, a := range answers {
+		answered[a.Key] = true
+	}
+	questions := []lifesheet.Question{}
+	remaining := 0
+	for _, q := range c.Questions {
+		if q.Key != "" && answered[q.Key] {
+			continue
+		}
+		if q.Key != "" {
+			remaining++
+		}
+		questions = append(questions, q)
+	}
+	if remaining == 0 {
+		return nil, nil
+	}
+	return questions, nil
+}
+
 // AskQuestions goes through each question and sends it through the bot.
 // We use multiple waits in this function to make sure the question
 // gets answered or we bail in time
-func (s *Scheduler) AskQuestions(questions []lifesheet.Question) {
+func (s *Scheduler) AskQuestions(category string, questions []lifesheet.Question) {
+	s.askSession(&session{category: category, questions: questions, answers: map[string]string{}})
+}
+
+func (s *Scheduler) askSession(sess *session) {
+	s.enqueue(sess)
+	s.sessionMu.Lock()
+	defer s.sessionMu.Unlock()
+	s.dequeue(sess)
 	var wg sync.WaitGroup
-	for _, q := range questions {
+	defer s.endSession(sess)
+	for i, q := range sess.questions {
+		if !askWhen(q, sess.answers) {
+			continue
+		}
 		ignoreQuestions := false
+		var snoozeFor time.Duration
 		// If a question is waiting a response, don't send the next one
 		wg.Add(1)
 		go func() {
@@ -103,24 +323,30 @@ func (s *Scheduler) AskQuestions(questions []lifesheet.Question) {
 					wg.Done()
 					break
 				}
+				time.Sleep(pollInterval)
 			}
 		}()
 		wg.Wait()
-		msg := q.Text
+		rendered, err := q.Render(s.templateData())
+		if err != nil {
+			log.WithError(err).Error("failed to render question template")
+		}
+		msg := rendered.Text
+		s.startQuestion(sess, i)
 		s.Bot.SendQuestion(bot.AskedQuestion{
 			Question: q.Text,
 			Text:     msg,
 			Key:      q.Key,
-			Replies:  q.Replies,
+			Replies:  rendered.Replies,
 			Type:     q.Type,
-			Buttons:  q.Buttons,
+			Buttons:  s.buttons(q),
 		})
 		if q.Type == "header" {
 			s.Bot.WaitingForResponse = false
 			continue
 		}
 		// Give the user a limited time to answer
-		ts := time.Now()
+		ts := s.clock.Now()
 		wg.Add(1)
 		go func() {
 			for {
@@ -128,17 +354,28 @@ func (s *Scheduler) AskQuestions(questions []lifesheet.Question) {
 					wg.Done()
 					break
 				}
-				now := time.Now()
-				if now.Sub(ts) > (30 * time.Minute) {
+				if d := s.snoozeRequested(sess); d > 0 {
+					snoozeFor = d
+					wg.Done()
+					break
+				}
+				if s.timedOut(ts) {
 					log.Info("timeout")
 					s.Bot.SendMessage("Maybe you're busy, no worry. We'll skip the check-in for now")
 					ignoreQuestions = true
 					wg.Done()
 					break
 				}
+				time.Sleep(pollInterval)
 			}
 		}()
 		wg.Wait()
+		// The user asked to be reminded later, park the remaining questions
+		if snoozeFor > 0 {
+			s.Bot.ResetQuestions()
+			s.snoozeSession(sess, i, snoozeFor)
+			break
+		}
 		// If the user didn't answer the question in time, assume they are busy
 		if ignoreQuestions {
 			s.Bot.ResetQuestions()
@@ -149,5 +386,96 @@ func (s *Scheduler) AskQuestions(questions []lifesheet.Question) {
 			s.Bot.ResetQuestions()
 			break
 		}
+		if answer := s.Bot.LastAnswer(); answer != "" {
+			sess.answers[q.Key] = answer
+		}
+	}
+}
+
+// askWhen evaluates the question's `when` condition against the answers
+// given earlier in the check-in. Invalid conditions are caught by the
+// lifesheet validation, but if one slips through the question is asked.
+func askWhen(q lifesheet.Question, answers map[string]string) bool {
+	if q.When == "" {
+		return true
+	}
+	cond, err := lifesheet.ParseCondition(q.When)
+	if err != nil {
+		log.WithError(err).WithField("key", q.Key).Error("invalid condition")
+		return true
+	}
+	return cond.Evaluate(answers)
+}
+
+// buttons returns the buttons to show for the question, adding a
+// "same as last time" button for number questions that ask for it
+func (s *Scheduler) buttons(q lifesheet.Question) map[string]string {
+	if q.Type != lifesheet.TypeNumber || !q.SameAsLast {
+		return q.Buttons
+	}
+	last, err := s.Database.GetLastAnswer(context.TODO(), q.Key)
+	if errors.Is(err, database.ErrNotFound) {
+		return q.Buttons
+	}
+	if err != nil {
+		log.WithError(err).Error("failed to get last answer")
+		return q.Buttons
+	}
+	val, err := database.NumericValue(last)
+	if err != nil {
+		log.WithError(err).Error("failed to parse last answer")
+		return q.Buttons
+	}
+	buttons := map[string]string{}
+	for k, v := range q.Buttons {
+		buttons[k] = v
+	}
+	value := lifesheet.FormatNumber(val)
+	buttons[value] = fmt.Sprintf("Same as last time (%s)", strings.TrimSpace(value+" "+q.Unit))
+	return buttons
+}
+
+// timedOut checks if the question asked at the given time has gone
+// unanswered for too long
+func (s *Scheduler) timedOut(asked time.Time) bool {
+	return s.clock.Now().Sub(asked) > questionTimeout
+}
+
+// enqueue records that the session is waiting for another check-in to finish
+func (s *Scheduler) enqueue(sess *session) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.queued = append(s.queued, sess)
+}
+
+// dequeue removes the session from the list of waiting check-ins
+func (s *Scheduler) dequeue(sess *session) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, q := range s.queued {
+		if q == sess {
+			s.queued = append(s.queued[:i], s.queued[i+1:]...)
+			return
+		}
+	}
+}
+
+// startQuestion marks the session as the active one
+func (s *Scheduler) startQuestion(sess *session, index int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sess.index = index
+	sess.asked = s.clock.Now()
+	s.active = sess
+}
+
+// endSession clears the active session if it's still the given one and
+// forgets the last question, so later messages aren't taken as answers
+func (s *Scheduler) endSession(sess *session) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.active == sess {
+		s.active = nil
 	}
+	s.Bot.ResetQuestions()
 }
@@ -0,0 +1,242 @@
+package scheduler
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/imdevinc/mylife/pkg/bot"
+	"github.com/imdevinc/mylife/pkg/clock"
+	"github.com/imdevinc/mylife/pkg/database"
+	"github.com/imdevinc/mylife/pkg/lifesheet"
+	"github.com/imdevinc/mylife/pkg/stats"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimedOut(t *testing.T) {
+	asked := time.Date(2022, 12, 31, 23, 45, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		elapsed time.Duration
+		want    bool
+	}{
+		{name: "just asked", elapsed: 0, want: false},
+		{name: "before timeout", elapsed: 29 * time.Minute, want: false},
+		{name: "exactly at timeout", elapsed: questionTimeout, want: false},
+		{name: "after timeout", elapsed: questionTimeout + time.Second, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clk := clock.NewMock(asked)
+			s := New(&SchedulerConfig{Clock: clk})
+			clk.Add(tt.elapsed)
+			assert.Equal(t, tt.want, s.timedOut(asked))
+		})
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		raw     string
+		want    time.Duration
+		wantErr bool
+	}{
+		{raw: "15m", want: 15 * time.Minute},
+		{raw: "1h30m", want: 90 * time.Minute},
+		{raw: "3d", want: 72 * time.Hour},
+		{raw: "2w", want: 14 * 24 * time.Hour},
+		{raw: "soon", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			got, err := ParseDuration(tt.raw)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestCommands(t *testing.T) {
+	s := New(&SchedulerConfig{Sheet: &lifesheet.Lifesheet{Categories: map[string]lifesheet.Category{
+		"mood":          {Description: "Track my mood"},
+		"asleep":        {},
+		"Weekly Review": {Description: "Not a valid command name"},
+		"status":        {Description: "Taken by a built-in command"},
+	}}})
+	commands := s.Commands()
+	if !assert.Len(t, commands, 2+len(builtinCommands)) {
+		t.FailNow()
+	}
+	assert.Equal(t, "asleep", commands[0].Name)
+	assert.Equal(t, "Ask the asleep questions", commands[0].Description)
+	assert.Equal(t, "mood", commands[1].Name)
+	assert.Equal(t, "Track my mood", commands[1].Description)
+	for _, c := range builtinCommands {
+		assert.True(t, lifesheet.IsBuiltinCommand(c.Name), "expected %s to be reserved in the lifesheet", c.Name)
+	}
+}
+
+func TestParseQuickLog(t *testing.T) {
+	tests := []struct {
+		name     string
+		question lifesheet.Question
+		args     []string
+		want     string
+		wantNote string
+		wantErr  bool
+	}{
+		{name: "number with note", question: lifesheet.Question{Key: "weight", Type: lifesheet.TypeNumber}, args: []string{"72.5", "after", "breakfast"}, want: "72.5", wantNote: "after breakfast"},
+		{name: "boolean", question: lifesheet.Question{Key: "workout", Type: lifesheet.TypeBoolean}, args: []string{"yes"}, want: "true"},
+		{name: "text uses every word", question: lifesheet.Question{Key: "gratitude", Type: lifesheet.TypeText}, args: []string{"Sunny", "walk"}, want: "Sunny walk"},
+		{name: "two word time", question: lifesheet.Question{Key: "bedtime", Type: lifesheet.TypeTime}, args: []string{"11:40", "pm", "late"}, want: "23:40", wantNote: "late"},
+		{name: "one word duration", question: lifesheet.Question{Key: "nap", Type: lifesheet.TypeDuration}, args: []string{"45m", "tired"}, want: "45m", wantNote: "tired"},
+		{name: "invalid boolean", question: lifesheet.Question{Key: "workout", Type: lifesheet.TypeBoolean}, args: []string{"maybe"}, wantErr: true},
+		{name: "range label", question: lifesheet.Question{Key: "mood", Type: lifesheet.TypeRange, Buttons: map[string]string{"5": "happy"}}, args: []string{"Happy", "sunny"}, want: "5", wantNote: "sunny"},
+		{name: "invalid range", question: lifesheet.Question{Key: "mood", Type: lifesheet.TypeRange, Buttons: map[string]string{"5": "happy"}}, args: []string{"7"}, wantErr: true},
+		{name: "photo", question: lifesheet.Question{Key: "meal", Type: lifesheet.TypePhoto}, args: []string{"pasta"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, note, err := parseQuickLog(tt.question, tt.args)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got.Text)
+			assert.Equal(t, tt.wantNote, note)
+		})
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	now := time.Date(2023, 1, 8, 22, 30, 0, 0, time.UTC)
+	answer := func(key string, daysAgo int, value string) database.AnswerResponse {
+		ts := now.AddDate(0, 0, -daysAgo).Add(-time.Hour)
+		return database.AnswerResponse{Key: key, Answer: value, Timestamp: ts.Unix(), Year: ts.Year(), Month: int(ts.Month()), Day: ts.Day()}
+	}
+	questions := []lifesheet.Question{
+		{Key: "mood", Type: lifesheet.TypeRange},
+		{Key: "grateful", Type: lifesheet.TypeText},
+		{Key: "workout", Type: lifesheet.TypeBoolean},
+	}
+	answers := []database.AnswerResponse{
+		answer("mood", 0, "4"),
+		answer("mood", 2, "3"),
+		answer("mood", 9, "2"),
+		answer("grateful", 1, "Sunny walk"),
+		answer("grateful", 8, "Too old"),
+		answer("workout", 0, "true"),
+		answer("workout", 1, "true"),
+		answer("workout", 3, "false"),
+	}
+
+	t.Run("week", func(t *testing.T) {
+		d := summarize(questions, answers, newDigestPeriod(digestWeek, now), map[string]string{"workout": "🔥 2 days in a row"})
+		assert.Equal(t, []string{
+			"📋 This week (Jan 2 - Jan 8)",
+			"mood: 3.5 (▲ 1.5 vs 2)",
+			"workout: done on 2 of 7 days, 🔥 2 days in a row",
+			"",
+			"🙏 Entries:",
+			"  • Sunny walk",
+		}, d.lines)
+		assert.NotEmpty(t, d.chartURL)
+	})
+
+	t.Run("day", func(t *testing.T) {
+		d := summarize(questions, answers, newDigestPeriod(digestDay, now), map[string]string{"workout": "🔥 2 days in a row"})
+		assert.Equal(t, []string{
+			"📋 Today (Sun Jan 8)",
+			"mood: 4",
+			"workout: ✅, 🔥 2 days in a row",
+		}, d.lines)
+		assert.Empty(t, d.chartURL)
+	})
+}
+
+func TestFormatChange(t *testing.T) {
+	assert.Equal(t, "▼ 45m", formatChange(lifesheet.Question{Type: lifesheet.TypeTime}, -45))
+	assert.Equal(t, "▲ 1h30m", formatChange(lifesheet.Question{Type: lifesheet.TypeDuration}, 5400))
+	assert.Equal(t, "=", formatChange(lifesheet.Question{Type: lifesheet.TypeNumber}, 0.01))
+}
+
+func TestAlertFires(t *testing.T) {
+	now := time.Date(2023, 1, 8, 12, 0, 0, 0, time.UTC)
+	answer := func(daysAgo int, value string) database.AnswerResponse {
+		ts := now.AddDate(0, 0, -daysAgo)
+		return database.AnswerResponse{Answer: value, Timestamp: ts.Unix(), Year: ts.Year(), Month: int(ts.Month()), Day: ts.Day()}
+	}
+	below := 2.0
+	mood := lifesheet.Question{Key: "mood", Type: lifesheet.TypeRange}
+	workout := lifesheet.Question{Key: "workout", Type: lifesheet.TypeBoolean}
+	lowMood := lifesheet.Alert{Below: &below, Days: 3}
+	noWorkout := lifesheet.Alert{Missing: true, Days: 7}
+	tests := []struct {
+		name     string
+		question lifesheet.Question
+		alert    lifesheet.Alert
+		answers  []database.AnswerResponse
+		want     bool
+	}{
+		{name: "low for 3 days", question: mood, alert: lowMood, answers: []database.AnswerResponse{answer(0, "1"), answer(1, "1"), answer(2, "0")}, want: true},
+		{name: "low until yesterday", question: mood, alert: lowMood, answers: []database.AnswerResponse{answer(1, "1"), answer(2, "1"), answer(3, "1")}, want: true},
+		{name: "average not low", question: mood, alert: lowMood, answers: []database.AnswerResponse{answer(0, "1"), answer(1, "1"), answer(1, "4"), answer(2, "0")}, want: false},
+		{name: "day without answers", question: mood, alert: lowMood, answers: []database.AnswerResponse{answer(0, "1"), answer(2, "1"), answer(3, "1")}, want: false},
+		{name: "no workout", question: workout, alert: noWorkout, answers: []database.AnswerResponse{answer(2, "false"), answer(8, "true")}, want: true},
+		{name: "recent workout", question: workout, alert: noWorkout, answers: []database.AnswerResponse{answer(6, "true")}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, alertFires(tt.question, tt.alert, tt.answers, now))
+		})
+	}
+}
+
+func TestSamples(t *testing.T) {
+	answers := []database.AnswerResponse{
+		{Key: "workout", Answer: "true", Year: 2023, Month: 1, Day: 1},
+		{Key: "workout", Answer: "false", Year: 2023, Month: 1, Day: 2},
+		{Key: "mood", Answer: "4", Year: 2023, Month: 1, Day: 2},
+	}
+	got := samples(lifesheet.Question{Key: "workout", Type: lifesheet.TypeBoolean}, answers)
+	assert.Equal(t, []stats.Sample{
+		{Day: stats.Day{Year: 2023, Month: time.January, Day: 1}, Value: 1},
+		{Day: stats.Day{Year: 2023, Month: time.January, Day: 2}, Value: 0},
+	}, got)
+}
+
+func TestSnippet(t *testing.T) {
+	long := strings.Repeat("a", 50) + " sunny walk " + strings.Repeat("b", 50)
+	tests := []struct {
+		name  string
+		text  string
+		terms []string
+		want  string
+	}{
+		{name: "short", text: "A sunny walk", terms: []string{"walk"}, want: "A sunny walk"},
+		{name: "around the match", text: long, terms: []string{"walk"}, want: "…" + strings.Repeat("a", 13) + " sunny walk " + strings.Repeat("b", 35) + "…"},
+		{name: "no match", text: long, terms: []string{"rain"}, want: strings.Repeat("a", 50) + " sunny wal…"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, snippet(tt.text, tt.terms, 60))
+		})
+	}
+}
+
+func TestEndSession(t *testing.T) {
+	telegram := &bot.Telegram{}
+	s := New(&SchedulerConfig{Bot: telegram})
+	sess := &session{category: "mood"}
+	s.startQuestion(sess, 0)
+	telegram.LastQuestion = bot.AskedQuestion{Key: "mood", Type: lifesheet.TypeRange}
+	telegram.NextQuestion("5")
+	s.endSession(sess)
+	assert.Nil(t, s.active)
+	assert.Equal(t, "", telegram.LastQuestion.Key)
+}
@@ -0,0 +1,119 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/imdevinc/mylife/pkg/database"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// searchPageSize is how many answers are shown per search message
+const searchPageSize = 5
+
+// snippetLength is the longest snippet shown for an answer, in characters
+const snippetLength = 120
+
+// maxCallbackData is the longest callback data Telegram accepts, in bytes
+const maxCallbackData = 64
+
+// Search handles `/search <terms>` by listing matching text answers,
+// newest first. The "More" button sends `/search @<offset> <terms>`
+// to show the next page.
+func (s *Scheduler) Search(args []string) {
+	offset := 0
+	if len(args) > 0 && strings.HasPrefix(args[0], "@") {
+		parsed, err := strconv.Atoi(strings.TrimPrefix(args[0], "@"))
+		if err != nil || parsed < 0 {
+			s.Bot.SendMessage(fmt.Sprintf("invalid search page %s", args[0]))
+			return
+		}
+		offset = parsed
+		args = args[1:]
+	}
+	query := strings.Join(args, " ")
+	if query == "" {
+		s.Bot.SendMessage("Usage: /search <terms>")
+		return
+	}
+	// Ask for one more answer to know if there is another page
+	answers, err := s.Database.SearchAnswers(context.TODO(), query, offset, searchPageSize+1)
+	if err != nil {
+		log.WithError(err).Error("failed to search answers")
+		s.Bot.SendMessage(fmt.Sprintf("failed to search answers. %s", err))
+		return
+	}
+	if len(answers) == 0 {
+		if offset == 0 {
+			s.Bot.SendMessage(fmt.Sprintf("No answers found for %s", query))
+		} else {
+			s.Bot.SendMessage("No more answers found")
+		}
+		return
+	}
+	more := len(answers) > searchPageSize
+	if more {
+		answers = answers[:searchPageSize]
+	}
+	message := searchResults(answers, database.SearchTerms(query))
+	next := fmt.Sprintf("/search @%d %s", offset+searchPageSize, query)
+	if !more || len(next) > maxCallbackData {
+		s.Bot.SendMessage(message)
+		return
+	}
+	if err := s.Bot.SendMessageWithButtons(message, map[string]string{next: "More"}); err != nil {
+		log.WithError(err).Error("failed to send search results")
+	}
+}
+
+// searchResults lists the answers with their date and a snippet
+func searchResults(answers []database.AnswerResponse, terms []string) string {
+	lines := []string{}
+	for _, a := range answers {
+		date := time.Date(a.Year, time.Month(a.Month), a.Day, 0, 0, 0, 0, time.UTC).Format("Jan 2 2006")
+		text := a.Answer
+		if a.Note != "" {
+			text += " (" + a.Note + ")"
+		}
+		lines = append(lines, fmt.Sprintf("%s · %s: %s", date, a.Key, snippet(text, terms, snippetLength)))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// snippet shortens the text to at most length characters, keeping the
+// first match of a term in view
+func snippet(text string, terms []string, length int) string {
+	runes := []rune(text)
+	if len(runes) <= length {
+		return text
+	}
+	lower := []rune(strings.ToLower(text))
+	match := -1
+	for _, t := range terms {
+		if i := strings.Index(string(lower), t); i >= 0 {
+			// Byte offset to rune offset
+			if at := len([]rune(string(lower)[:i])); match < 0 || at < match {
+				match = at
+			}
+		}
+	}
+	start := 0
+	if match > length/3 {
+		start = match - length/3
+	}
+	if start+length > len(runes) {
+		start = len(runes) - length
+	}
+	out := string(runes[start : start+length])
+	if start > 0 {
+		out = "…" + out
+	}
+	if start+length < len(runes) {
+		out += "…"
+	}
+	return out
+}
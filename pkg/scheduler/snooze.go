@@ -0,0 +1,75 @@
+package scheduler
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/imdevinc/mylife/pkg/lifesheet"
+)
+
+const defaultSnooze = 15 * time.Minute
+
+// snoozedSession holds the questions that will be re-asked once
+// the snooze is over
+type snoozedSession struct {
+	category  string
+	questions []lifesheet.Question
+	answers   map[string]string
+	until     time.Time
+}
+
+// Snooze handles the /snooze command. It asks the running AskQuestions
+// session to park the remaining questions for the given duration
+// (15 minutes by default).
+func (s *Scheduler) Snooze(args []string) {
+	d := defaultSnooze
+	if len(args) > 0 {
+		parsed, err := ParseDuration(args[0])
+		if err != nil || parsed <= 0 {
+			s.Bot.SendMessage(fmt.Sprintf("invalid snooze duration %s, try 15m or 1h", args[0]))
+			return
+		}
+		d = parsed
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.active == nil {
+		s.Bot.SendMessage("There is no check-in to snooze")
+		return
+	}
+	s.active.snooze = d
+}
+
+// snoozeRequested returns the requested snooze duration for the session,
+// or 0 if the user hasn't asked to snooze it
+func (s *Scheduler) snoozeRequested(sess *session) time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return sess.snooze
+}
+
+// snoozeSession parks the questions from index onwards and asks them
+// again once the snooze is over, keeping the answers given so far
+func (s *Scheduler) snoozeSession(sess *session, index int, d time.Duration) {
+	snoozed := &snoozedSession{
+		category:  sess.category,
+		questions: sess.questions[index:],
+		answers:   sess.answers,
+		until:     s.clock.Now().Add(d),
+	}
+	s.mu.Lock()
+	s.snoozes = append(s.snoozes, snoozed)
+	s.mu.Unlock()
+	time.AfterFunc(d, func() {
+		s.mu.Lock()
+		for i, sn := range s.snoozes {
+			if sn == snoozed {
+				s.snoozes = append(s.snoozes[:i], s.snoozes[i+1:]...)
+				break
+			}
+		}
+		s.mu.Unlock()
+		s.askSession(&session{category: snoozed.category, questions: snoozed.questions, answers: snoozed.answers})
+	})
+	s.Bot.SendMessage(fmt.Sprintf("Snoozed, I'll ask again at %s", snoozed.until.In(s.Location()).Format("15:04")))
+}
@@ -0,0 +1,67 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Status handles the /status command by reporting the current check-in,
+// queued and snoozed check-ins, pauses and the upcoming schedule
+func (s *Scheduler) Status() {
+	now := s.clock.Now().In(s.Location())
+	lines := []string{}
+
+	s.mu.Lock()
+	if s.active != nil {
+		line := fmt.Sprintf("Current check-in: %s (question %d of %d)", s.active.category, s.active.index+1, len(s.active.questions))
+		if s.Bot.WaitingForResponse {
+			left := questionTimeout - now.Sub(s.active.asked)
+			if left < 0 {
+				left = 0
+			}
+			line += fmt.Sprintf(", waiting for \"%s\", %s left to answer", s.Bot.LastQuestion.Key, left.Round(time.Minute))
+		}
+		lines = append(lines, line)
+	} else {
+		lines = append(lines, "No check-in in progress")
+	}
+	for _, q := range s.queued {
+		lines = append(lines, fmt.Sprintf("Queued: %s, %d questions", q.category, len(q.questions)))
+	}
+	for _, sn := range s.snoozes {
+		lines = append(lines, fmt.Sprintf("Snoozed: %s, %d questions at %s", sn.category, len(sn.questions), sn.until.In(now.Location()).Format("15:04")))
+	}
+	lines = append(lines, s.upcoming(now)...)
+	s.mu.Unlock()
+
+	pauses, err := s.getPauses(context.TODO())
+	if err != nil {
+		log.WithError(err).Error("failed to get pauses")
+		lines = append(lines, fmt.Sprintf("failed to get pauses from database. %s", err))
+	} else {
+		lines = append(lines, pauseStatus(pauses, now))
+	}
+	s.Bot.SendMessage(strings.Join(lines, "\n"))
+}
+
+// upcoming lists the next run of every scheduled check-in, soonest first.
+// The caller must hold s.mu.
+func (s *Scheduler) upcoming(now time.Time) []string {
+	if s.cron == nil {
+		return nil
+	}
+	jobs := s.cron.Jobs()
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].NextRun().Before(jobs[j].NextRun())
+	})
+	lines := []string{"Next check-ins:"}
+	for _, j := range jobs {
+		lines = append(lines, fmt.Sprintf("  %s at %s", strings.Join(j.Tags(), ","), j.NextRun().In(now.Location()).Format("Mon Jan 2 15:04")))
+	}
+	return lines
+}
@@ -0,0 +1,88 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/imdevinc/mylife/pkg/database"
+	"github.com/imdevinc/mylife/pkg/lifesheet"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// streak returns the streaks for the boolean question, counted in weeks
+// if its category is asked weekly and skipping the days it was paused
+func (s *Scheduler) streak(ctx context.Context, key string) (database.Streak, error) {
+	category := s.questionCategory(key)
+	pauses, err := s.getPauses(ctx)
+	if err != nil {
+		return database.Streak{}, err
+	}
+	relevant := []database.Pause{}
+	for _, p := range pauses {
+		if p.Category == "" || p.Category == category {
+			relevant = append(relevant, p)
+		}
+	}
+	return s.Database.GetStreak(ctx, key, database.StreakOptions{
+		Weekly: s.Sheet.Categories[category].Schedule == lifesheet.ScheduleWeekly,
+		Pauses: relevant,
+		Now:    s.clock.Now().In(s.Location()),
+	})
+}
+
+// streakUnit returns what the question's streak is counted in
+func (s *Scheduler) streakUnit(key string) string {
+	if s.Sheet.Categories[s.questionCategory(key)].Schedule == lifesheet.ScheduleWeekly {
+		return "weeks"
+	}
+	return "days"
+}
+
+// ReplyStreak sends the current streak after a boolean question is
+// answered with true, once it's longer than a single day or week
+func (s *Scheduler) ReplyStreak(key string) {
+	streak, err := s.streak(context.TODO(), key)
+	if err != nil {
+		log.WithError(err).Error("failed to get streak")
+		return
+	}
+	if streak.Current > 1 {
+		s.Bot.SendMessage(formatStreak(streak.Current, s.streakUnit(key)))
+	}
+}
+
+// Streaks handles the /streaks command by listing the current and
+// longest streak of every boolean question
+func (s *Scheduler) Streaks() {
+	lines := []string{}
+	for _, name := range s.categoryNames() {
+		for _, q := range s.Sheet.Categories[name].Questions {
+			if q.Type != lifesheet.TypeBoolean || q.Key == "" {
+				continue
+			}
+			streak, err := s.streak(context.TODO(), q.Key)
+			if err != nil {
+				log.WithError(err).Error("failed to get streak")
+				s.Bot.SendMessage(fmt.Sprintf("failed to get streaks from database. %s", err))
+				return
+			}
+			unit := s.streakUnit(q.Key)
+			line := fmt.Sprintf("%s: no streak", q.Key)
+			if streak.Current > 0 {
+				line = fmt.Sprintf("%s: %s", q.Key, formatStreak(streak.Current, unit))
+			}
+			lines = append(lines, fmt.Sprintf("%s (longest %d %s)", line, streak.Longest, unit))
+		}
+	}
+	if len(lines) == 0 {
+		s.Bot.SendMessage("There are no yes/no questions to keep streaks for")
+		return
+	}
+	s.Bot.SendMessage(strings.Join(lines, "\n"))
+}
+
+func formatStreak(n int, unit string) string {
+	return fmt.Sprintf("🔥 %d %s in a row", n, unit)
+}
@@ -0,0 +1,53 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/imdevinc/mylife/pkg/database"
+	"github.com/imdevinc/mylife/pkg/stats"
+)
+
+// templateData answers question template lookups from the database
+type templateData struct {
+	ctx    context.Context
+	db     database.Database
+	now    time.Time
+	streak func(ctx context.Context, key string) (database.Streak, error)
+}
+
+func (s *Scheduler) templateData() templateData {
+	return templateData{ctx: context.TODO(), db: s.Database, now: s.clock.Now().In(s.Location()), streak: s.streak}
+}
+
+func (d templateData) Last(key string) (string, error) {
+	last, err := d.db.GetLastAnswer(d.ctx, key)
+	if errors.Is(err, database.ErrNotFound) {
+		return "?", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return last.Answer, nil
+}
+
+func (d templateData) Streak(key string) (int, error) {
+	streak, err := d.streak(d.ctx, key)
+	if err != nil {
+		return 0, err
+	}
+	return streak.Current, nil
+}
+
+func (d templateData) Average(key string, days int) (float64, error) {
+	answers, err := d.db.GetAnswersSince(d.ctx, []string{key}, d.now.AddDate(0, 0, -days))
+	if err != nil {
+		return 0, err
+	}
+	return stats.Mean(numericValues(answers)), nil
+}
+
+func dayKey(year int, month time.Month, day int) string {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC).Format("2006-01-02")
+}
@@ -0,0 +1,91 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/imdevinc/mylife/pkg/database"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const timezoneSetting string = "timezone"
+
+// Location returns the timezone check-ins are currently scheduled in
+func (s *Scheduler) Location() *time.Location {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.location
+}
+
+// Now returns the current time in the home timezone
+func (s *Scheduler) Now() time.Time {
+	return s.clock.Now().In(s.Location())
+}
+
+// loadSavedTimezone replaces the configured home timezone with the one
+// saved through /timezone, if any
+func (s *Scheduler) loadSavedTimezone(ctx context.Context) error {
+	var name string
+	err := s.Database.GetSetting(ctx, timezoneSetting, &name)
+	if errors.Is(err, database.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	loc, err := loadTimezone(name)
+	if err != nil {
+		return fmt.Errorf("invalid saved timezone %s. %w", name, err)
+	}
+	s.mu.Lock()
+	s.location = loc
+	s.mu.Unlock()
+	return nil
+}
+
+// SetTimezone handles the /timezone command. Without arguments it replies
+// with the current timezone, otherwise it saves the given IANA zone and
+// reschedules every check-in in it.
+func (s *Scheduler) SetTimezone(args []string) {
+	if len(args) == 0 {
+		s.Bot.SendMessage(fmt.Sprintf("Current timezone is %s", s.Location()))
+		return
+	}
+	loc, err := loadTimezone(args[0])
+	if err != nil {
+		s.Bot.SendMessage(fmt.Sprintf("unknown timezone %s, try something like Europe/Berlin", args[0]))
+		return
+	}
+	if err := s.Database.SaveSetting(context.TODO(), timezoneSetting, loc.String()); err != nil {
+		log.WithError(err).Error("failed to save timezone")
+		s.Bot.SendMessage(fmt.Sprintf("failed to save timezone to database. %s", err))
+		return
+	}
+	s.mu.Lock()
+	s.location = loc
+	if s.cron != nil {
+		// Existing jobs keep their old run times, so recreate them
+		s.cron.ChangeLocation(loc)
+		s.cron.Clear()
+		err = s.scheduleJobs()
+	}
+	s.mu.Unlock()
+	if err != nil {
+		log.WithError(err).Error("failed to reschedule check-ins")
+		s.Bot.SendMessage(fmt.Sprintf("failed to reschedule check-ins. %s", err))
+		return
+	}
+	s.Bot.SendMessage(fmt.Sprintf("Timezone set to %s, it's %s there now", loc, s.clock.Now().In(loc).Format("15:04")))
+}
+
+// loadTimezone loads an IANA timezone. "Local" is rejected since it
+// depends on the host and is stored with every answer.
+func loadTimezone(name string) (*time.Location, error) {
+	if name == "Local" {
+		return nil, fmt.Errorf("timezone Local depends on the host, use a name like Europe/Berlin")
+	}
+	return time.LoadLocation(name)
+}
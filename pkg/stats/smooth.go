@@ -0,0 +1,74 @@
+package stats
+
+import (
+	"errors"
+	"time"
+)
+
+// MovingAverage returns, for every value, the mean of the values in the
+// window ending at its time. Times must be sorted.
+func MovingAverage(times []time.Time, values []float64, window time.Duration) []float64 {
+	out := make([]float64, len(values))
+	start := 0
+	total := 0.0
+	for i, v := range values {
+		total += v
+		for !times[start].After(times[i].Add(-window)) {
+			total -= values[start]
+			start++
+		}
+		out[i] = total / float64(i-start+1)
+	}
+	return out
+}
+
+// WeeklyMeans replaces every value with the mean of its ISO week,
+// in the location of its time
+func WeeklyMeans(times []time.Time, values []float64) []float64 {
+	type week struct{ year, week int }
+	totals := map[week]float64{}
+	counts := map[week]int{}
+	weeks := make([]week, len(values))
+	for i, t := range times {
+		year, w := t.ISOWeek()
+		weeks[i] = week{year, w}
+		totals[weeks[i]] += values[i]
+		counts[weeks[i]]++
+	}
+	out := make([]float64, len(values))
+	for i, w := range weeks {
+		out[i] = totals[w] / float64(counts[w])
+	}
+	return out
+}
+
+// LinearTrend fits a least squares line through the values over time and
+// returns its value at every time
+func LinearTrend(times []time.Time, values []float64) ([]float64, error) {
+	if len(values) < 2 {
+		return nil, ErrNotEnoughData
+	}
+	if len(times) != len(values) {
+		return nil, errors.New("samples have different lengths")
+	}
+	// Days since the first value keep the numbers small
+	xs := make([]float64, len(times))
+	for i, t := range times {
+		xs[i] = t.Sub(times[0]).Hours() / 24
+	}
+	mx, my := Mean(xs), Mean(values)
+	var cov, vx float64
+	for i := range xs {
+		cov += (xs[i] - mx) * (values[i] - my)
+		vx += (xs[i] - mx) * (xs[i] - mx)
+	}
+	if vx == 0 {
+		return nil, ErrNotEnoughData
+	}
+	slope := cov / vx
+	out := make([]float64, len(xs))
+	for i, x := range xs {
+		out[i] = my + slope*(x-mx)
+	}
+	return out, nil
+}
@@ -0,0 +1,41 @@
+package stats_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/imdevinc/mylife/pkg/stats"
+	"github.com/stretchr/testify/assert"
+)
+
+func days(offsets ...int) []time.Time {
+	start := time.Date(2023, 1, 2, 12, 0, 0, 0, time.UTC)
+	times := make([]time.Time, len(offsets))
+	for i, o := range offsets {
+		times[i] = start.AddDate(0, 0, o)
+	}
+	return times
+}
+
+func TestMovingAverage(t *testing.T) {
+	times := days(0, 1, 1, 3, 7, 8)
+	values := []float64{1, 2, 3, 4, 5, 9}
+	got := stats.MovingAverage(times, values, 7*24*time.Hour)
+	// Day 7 drops day 0, day 8 drops both values of day 1
+	assert.Equal(t, []float64{1, 1.5, 2, 2.5, 3.5, 6}, got)
+}
+
+func TestWeeklyMeans(t *testing.T) {
+	// Jan 2 2023 is a Monday
+	times := days(0, 6, 7, 8)
+	assert.Equal(t, []float64{2, 2, 5, 5}, stats.WeeklyMeans(times, []float64{1, 3, 4, 6}))
+}
+
+func TestLinearTrend(t *testing.T) {
+	got, err := stats.LinearTrend(days(0, 1, 2, 3), []float64{1, 3, 2, 4})
+	assert.NoError(t, err)
+	assert.InDeltaSlice(t, []float64{1.3, 2.1, 2.9, 3.7}, got, 0.0001)
+
+	_, err = stats.LinearTrend(days(0), []float64{1})
+	assert.ErrorIs(t, err, stats.ErrNotEnoughData)
+}
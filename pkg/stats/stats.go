@@ -0,0 +1,192 @@
+// Package stats compares the answers of different questions
+package stats
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"time"
+)
+
+// ErrNotEnoughData is returned when there are too few pairs,
+// or no variation, to compute a correlation
+var ErrNotEnoughData = errors.New("not enough data")
+
+// minPairs is the smallest sample a correlation is computed for
+const minPairs = 3
+
+// Day identifies a calendar day, independent of timezones
+type Day struct {
+	Year  int
+	Month time.Month
+	Day   int
+}
+
+// AddDays returns the day n days later
+func (d Day) AddDays(n int) Day {
+	t := time.Date(d.Year, d.Month, d.Day+n, 0, 0, 0, 0, time.UTC)
+	return Day{Year: t.Year(), Month: t.Month(), Day: t.Day()}
+}
+
+// Before reports whether d is before other
+func (d Day) Before(other Day) bool {
+	if d.Year != other.Year {
+		return d.Year < other.Year
+	}
+	if d.Month != other.Month {
+		return d.Month < other.Month
+	}
+	return d.Day < other.Day
+}
+
+// Sample is a single value recorded on a day
+type Sample struct {
+	Day   Day
+	Value float64
+}
+
+// DailyMeans averages the samples of each day
+func DailyMeans(samples []Sample) map[Day]float64 {
+	totals := map[Day]float64{}
+	counts := map[Day]int{}
+	for _, s := range samples {
+		totals[s.Day] += s.Value
+		counts[s.Day]++
+	}
+	means := map[Day]float64{}
+	for d, total := range totals {
+		means[d] = total / float64(counts[d])
+	}
+	return means
+}
+
+// Pair is the value of a and b for the same day,
+// or b lagging behind a by some days
+type Pair struct {
+	Day  Day
+	X, Y float64
+}
+
+// Align pairs every day of a with the day lag days later in b, skipping
+// days missing from either. A lag of 1 compares a day with the next one.
+// Pairs are sorted by day.
+func Align(a, b map[Day]float64, lag int) []Pair {
+	pairs := []Pair{}
+	for d, x := range a {
+		y, ok := b[d.AddDays(lag)]
+		if !ok {
+			continue
+		}
+		pairs = append(pairs, Pair{Day: d, X: x, Y: y})
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		return pairs[i].Day.Before(pairs[j].Day)
+	})
+	return pairs
+}
+
+// Split returns the x and y values of the pairs
+func Split(pairs []Pair) ([]float64, []float64) {
+	xs := make([]float64, len(pairs))
+	ys := make([]float64, len(pairs))
+	for i, p := range pairs {
+		xs[i] = p.X
+		ys[i] = p.Y
+	}
+	return xs, ys
+}
+
+// Pearson computes the linear correlation coefficient of xs and ys
+func Pearson(xs, ys []float64) (float64, error) {
+	if len(xs) != len(ys) {
+		return 0, errors.New("samples have different lengths")
+	}
+	if len(xs) < minPairs {
+		return 0, ErrNotEnoughData
+	}
+	mx, my := Mean(xs), Mean(ys)
+	var cov, vx, vy float64
+	for i := range xs {
+		dx, dy := xs[i]-mx, ys[i]-my
+		cov += dx * dy
+		vx += dx * dx
+		vy += dy * dy
+	}
+	if vx == 0 || vy == 0 {
+		return 0, ErrNotEnoughData
+	}
+	return cov / math.Sqrt(vx*vy), nil
+}
+
+// Spearman computes the rank correlation coefficient of xs and ys,
+// which also picks up relationships that are monotonic but not linear
+func Spearman(xs, ys []float64) (float64, error) {
+	if len(xs) != len(ys) {
+		return 0, errors.New("samples have different lengths")
+	}
+	return Pearson(Ranks(xs), Ranks(ys))
+}
+
+// Ranks replaces every value with its rank, starting at 1.
+// Tied values get the average of their ranks.
+func Ranks(values []float64) []float64 {
+	order := make([]int, len(values))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return values[order[i]] < values[order[j]]
+	})
+	ranks := make([]float64, len(values))
+	for i := 0; i < len(order); {
+		j := i
+		for j+1 < len(order) && values[order[j+1]] == values[order[i]] {
+			j++
+		}
+		rank := float64(i+j)/2 + 1
+		for k := i; k <= j; k++ {
+			ranks[order[k]] = rank
+		}
+		i = j + 1
+	}
+	return ranks
+}
+
+// Mean returns the average of the values
+func Mean(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	total := 0.0
+	for _, v := range values {
+		total += v
+	}
+	return total / float64(len(values))
+}
+
+// Bounds returns the smallest and largest values
+func Bounds(values []float64) (float64, float64) {
+	var min, max float64
+	for i, v := range values {
+		if i == 0 || v < min {
+			min = v
+		}
+		if i == 0 || v > max {
+			max = v
+		}
+	}
+	return min, max
+}
+
+// Strength describes the size of a correlation coefficient
+func Strength(r float64) string {
+	switch r = math.Abs(r); {
+	case r >= 0.7:
+		return "strong"
+	case r >= 0.4:
+		return "moderate"
+	case r >= 0.2:
+		return "weak"
+	}
+	return "no"
+}
@@ -0,0 +1,64 @@
+package stats_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/imdevinc/mylife/pkg/stats"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAlign(t *testing.T) {
+	day := func(month time.Month, d int) stats.Day {
+		return stats.Day{Year: 2022, Month: month, Day: d}
+	}
+	sleep := stats.DailyMeans([]stats.Sample{
+		{Day: day(12, 30), Value: 6},
+		{Day: day(12, 31), Value: 8},
+		{Day: day(12, 31), Value: 7},
+	})
+	mood := map[stats.Day]float64{
+		day(12, 31): 3,
+		{Year: 2023, Month: time.January, Day: 1}: 5,
+	}
+	assert.Equal(t, []stats.Pair{{Day: day(12, 31), X: 7.5, Y: 3}}, stats.Align(sleep, mood, 0))
+	assert.Equal(t, []stats.Pair{
+		{Day: day(12, 30), X: 6, Y: 3},
+		{Day: day(12, 31), X: 7.5, Y: 5},
+	}, stats.Align(sleep, mood, 1))
+}
+
+func TestCorrelation(t *testing.T) {
+	tests := []struct {
+		name     string
+		xs, ys   []float64
+		pearson  float64
+		spearman float64
+		wantErr  bool
+	}{
+		{name: "linear", xs: []float64{1, 2, 3, 4}, ys: []float64{2, 4, 6, 8}, pearson: 1, spearman: 1},
+		{name: "inverse", xs: []float64{1, 2, 3, 4}, ys: []float64{8, 6, 4, 2}, pearson: -1, spearman: -1},
+		{name: "monotonic", xs: []float64{1, 2, 3, 4, 5}, ys: []float64{1, 4, 9, 16, 100}, pearson: 0.7952, spearman: 1},
+		{name: "ties", xs: []float64{1, 1, 2, 3}, ys: []float64{1, 2, 2, 3}, pearson: 0.8528, spearman: 0.8333},
+		{name: "too few", xs: []float64{1, 2}, ys: []float64{1, 2}, wantErr: true},
+		{name: "no variation", xs: []float64{1, 1, 1}, ys: []float64{1, 2, 3}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pearson, err := stats.Pearson(tt.xs, tt.ys)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, stats.ErrNotEnoughData)
+				return
+			}
+			assert.NoError(t, err)
+			assert.InDelta(t, tt.pearson, pearson, 0.0001)
+			spearman, err := stats.Spearman(tt.xs, tt.ys)
+			assert.NoError(t, err)
+			assert.InDelta(t, tt.spearman, spearman, 0.0001)
+		})
+	}
+}
+
+func TestRanks(t *testing.T) {
+	assert.Equal(t, []float64{3, 1.5, 1.5, 4}, stats.Ranks([]float64{5, 2, 2, 9}))
+}
@@ -0,0 +1,44 @@
+package transcribe
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// Transcriber turns a voice note into text
+type Transcriber interface {
+	// Transcribe returns the text spoken in the audio file at path
+	Transcribe(ctx context.Context, path string) (string, error)
+}
+
+// Command is a Transcriber that runs a local command with the audio
+// file path as its last argument and uses its output as the transcript
+type Command struct {
+	name string
+	args []string
+}
+
+// NewCommand creates a Command from a command line such as
+// "whisper --model base --output_format txt"
+func NewCommand(command string) (*Command, error) {
+	fields := strings.Fields(command)
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("transcribe command is empty")
+	}
+	return &Command{name: fields[0], args: fields[1:]}, nil
+}
+
+func (c *Command) Transcribe(ctx context.Context, path string) (string, error) {
+	args := append(append([]string{}, c.args...), path)
+	cmd := exec.CommandContext(ctx, c.name, args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to transcribe %s. %v: %s", path, err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(string(out)), nil
+}
@@ -0,0 +1,33 @@
+package transcribe_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/imdevinc/mylife/pkg/transcribe"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCommand(t *testing.T) {
+	cmd, err := transcribe.NewCommand("echo grateful for")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	transcript, err := cmd.Transcribe(context.TODO(), "voice.ogg")
+	assert.NoError(t, err)
+	assert.Equal(t, "grateful for voice.ogg", transcript)
+}
+
+func TestCommandFailure(t *testing.T) {
+	cmd, err := transcribe.NewCommand("false")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	_, err = cmd.Transcribe(context.TODO(), "voice.ogg")
+	assert.Error(t, err)
+}
+
+func TestEmptyCommand(t *testing.T) {
+	_, err := transcribe.NewCommand("  ")
+	assert.Error(t, err)
+}